@@ -13,6 +13,8 @@ const (
 	AutoMode   Mode = "auto"
 	ClaudeMode Mode = "claude"
 	CodexMode  Mode = "codex"
+	// FakeMode selects the built-in fake agent, which needs no binary.
+	FakeMode Mode = "fake"
 )
 
 type Name string
@@ -20,6 +22,9 @@ type Name string
 const (
 	ClaudeAgent Name = "claude"
 	CodexAgent  Name = "codex"
+	// FakeAgent follows a script instead of calling a model. Its argv is
+	// informational; Dependencies.Exec must run it in process.
+	FakeAgent Name = "fake"
 )
 
 type LookPathFunc func(file string) (string, error)
@@ -29,7 +34,7 @@ type InvalidModeError struct {
 }
 
 func (e *InvalidModeError) Error() string {
-	return fmt.Sprintf("invalid agent mode %q (expected auto, claude, or codex)", e.Raw)
+	return fmt.Sprintf("invalid agent mode %q (expected auto, claude, codex, or fake)", e.Raw)
 }
 
 type NotFoundError struct {
@@ -43,7 +48,7 @@ func (e *NotFoundError) Error() string {
 func ParseMode(raw string) (Mode, error) {
 	mode := Mode(strings.TrimSpace(strings.ToLower(raw)))
 	switch mode {
-	case AutoMode, ClaudeMode, CodexMode:
+	case AutoMode, ClaudeMode, CodexMode, FakeMode:
 		return mode, nil
 	default:
 		return "", &InvalidModeError{Raw: raw}
@@ -64,6 +69,8 @@ func Resolve(mode Mode, lookPath LookPathFunc) (Name, error) {
 		return resolveExplicit(ClaudeAgent, lookPath)
 	case CodexMode:
 		return resolveExplicit(CodexAgent, lookPath)
+	case FakeMode:
+		return FakeAgent, nil
 	default:
 		return "", &InvalidModeError{Raw: string(mode)}
 	}
@@ -88,6 +95,9 @@ func resolveExplicit(agent Name, lookPath LookPathFunc) (Name, error) {
 type CommandOptions struct {
 	Interactive                bool
 	DangerouslyAllowAllActions bool
+	// Resume continues the agent's most recent session in the working
+	// directory instead of starting a new one.
+	Resume bool
 }
 
 func CommandArgs(agent Name, prompt string, opts CommandOptions) ([]string, error) {
@@ -97,6 +107,9 @@ func CommandArgs(agent Name, prompt string, opts CommandOptions) ([]string, erro
 		if !opts.Interactive {
 			args = append(args, "-p")
 		}
+		if opts.Resume {
+			args = append(args, "--continue")
+		}
 		args = append(args, "--permission-mode", "acceptEdits")
 		if opts.DangerouslyAllowAllActions {
 			args = append(args, "--dangerously-skip-permissions")
@@ -111,6 +124,16 @@ func CommandArgs(agent Name, prompt string, opts CommandOptions) ([]string, erro
 		if opts.DangerouslyAllowAllActions {
 			args = append(args, "--dangerously-bypass-approvals-and-sandbox")
 		}
+		if opts.Resume {
+			args = append(args, "resume", "--last")
+		}
+		args = append(args, prompt)
+		return args, nil
+	case FakeAgent:
+		args := []string{string(FakeAgent)}
+		if opts.Resume {
+			args = append(args, "--resume")
+		}
 		args = append(args, prompt)
 		return args, nil
 	default:
@@ -106,6 +106,38 @@ func TestCommandArgs(t *testing.T) {
 				"p",
 			},
 		},
+		{
+			name:   "claude batch resume",
+			agent:  ClaudeAgent,
+			prompt: "p",
+			opts: CommandOptions{
+				Resume: true,
+			},
+			want: []string{"claude", "-p", "--continue", "--permission-mode", "acceptEdits", "p"},
+		},
+		{
+			name:   "codex batch resume dangerous",
+			agent:  CodexAgent,
+			prompt: "p",
+			opts: CommandOptions{
+				DangerouslyAllowAllActions: true,
+				Resume:                     true,
+			},
+			want: []string{
+				"codex",
+				"exec",
+				"--dangerously-bypass-approvals-and-sandbox",
+				"resume", "--last",
+				"p",
+			},
+		},
+		{
+			name:   "fake resume",
+			agent:  FakeAgent,
+			prompt: "p",
+			opts:   CommandOptions{Resume: true},
+			want:   []string{"fake", "--resume", "p"},
+		},
 		{name: "invalid", agent: Name("other"), prompt: "p", wantErr: true},
 	}
 
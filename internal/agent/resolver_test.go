@@ -84,6 +84,20 @@ func TestResolveExplicitModeRequiresThatBinary(t *testing.T) {
 	}
 }
 
+func TestResolveFakeNeedsNoBinary(t *testing.T) {
+	lookPath := func(string) (string, error) {
+		return "", exec.ErrNotFound
+	}
+
+	got, err := Resolve(FakeMode, lookPath)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if got != FakeAgent {
+		t.Fatalf("Resolve = %q, want %q", got, FakeAgent)
+	}
+}
+
 func TestParseMode(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -94,6 +108,7 @@ func TestParseMode(t *testing.T) {
 		{name: "auto", raw: "auto", want: AutoMode},
 		{name: "claude", raw: "claude", want: ClaudeMode},
 		{name: "codex", raw: "codex", want: CodexMode},
+		{name: "fake", raw: "fake", want: FakeMode},
 		{name: "trim and case", raw: "  CoDeX ", want: CodexMode},
 		{name: "invalid", raw: "other", wantErr: true},
 	}
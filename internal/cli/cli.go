@@ -5,13 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os/exec"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
-	"github.com/PeronGH/mdtest-cli/internal/agent"
-	"github.com/PeronGH/mdtest-cli/internal/procexec"
-	"github.com/PeronGH/mdtest-cli/internal/run"
+	"github.com/PeronGH/mdtest-cli/mdtest"
 )
 
 const (
@@ -33,17 +35,18 @@ func (e *ExitError) Unwrap() error {
 	return e.Err
 }
 
-func Execute(args []string, stdout, stderr io.Writer, lookPath agent.LookPathFunc) int {
+func Execute(args []string, stdout, stderr io.Writer, lookPath mdtest.LookPathFunc) int {
 	return executeWithDeps(args, stdout, stderr, lookPath, defaultRunSuite(stdout))
 }
 
-type RunSuiteFunc func(ctx context.Context, cfg run.Config) (run.SuiteResult, error)
+// RunSuiteFunc runs a suite, sending events to reporters.
+type RunSuiteFunc func(ctx context.Context, cfg mdtest.Config, reporters []mdtest.Reporter) (mdtest.SuiteResult, error)
 
 func executeWithDeps(
 	args []string,
 	stdout io.Writer,
 	stderr io.Writer,
-	lookPath agent.LookPathFunc,
+	lookPath mdtest.LookPathFunc,
 	runSuite RunSuiteFunc,
 ) int {
 	root := NewRootCmd(stdout, stderr, lookPath, runSuite)
@@ -62,7 +65,7 @@ func executeWithDeps(
 	return ExitOK
 }
 
-func NewRootCmd(stdout, stderr io.Writer, lookPath agent.LookPathFunc, runSuite RunSuiteFunc) *cobra.Command {
+func NewRootCmd(stdout, stderr io.Writer, lookPath mdtest.LookPathFunc, runSuite RunSuiteFunc) *cobra.Command {
 	root := &cobra.Command{
 		Use:           "mdtest",
 		SilenceErrors: true,
@@ -71,68 +74,313 @@ func NewRootCmd(stdout, stderr io.Writer, lookPath agent.LookPathFunc, runSuite
 	root.SetOut(stdout)
 	root.SetErr(stderr)
 	root.AddCommand(newRunCmd(lookPath, runSuite))
+	root.AddCommand(newPromptCmd(stdout))
+	root.AddCommand(newValidateLogCmd(stdout))
 	return root
 }
 
-func newRunCmd(lookPath agent.LookPathFunc, runSuite RunSuiteFunc) *cobra.Command {
-	agentFlag := string(agent.AutoMode)
+func newValidateLogCmd(stdout io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate-log <log.md>...",
+		Short: "Check agent logs against the log schema",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			invalid := 0
+			for _, path := range args {
+				diags, err := mdtest.ValidateLog(path)
+				if err != nil {
+					return &ExitError{Code: ExitSetupError, Err: fmt.Errorf("%s: %w", path, err)}
+				}
+				if len(diags) == 0 {
+					_, _ = fmt.Fprintf(stdout, "%s: ok\n", path)
+					continue
+				}
+				invalid++
+				for _, diag := range diags {
+					_, _ = fmt.Fprintf(stdout, "%s:%d: %s\n", path, diag.Line, diag.Message)
+				}
+			}
+			if invalid > 0 {
+				return &ExitError{Code: ExitFailed, Err: fmt.Errorf("%d log(s) invalid", invalid)}
+			}
+			return nil
+		},
+	}
+}
+
+func newPromptCmd(stdout io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Inspect agent prompts",
+	}
+	cmd.AddCommand(newPromptRenderCmd(stdout))
+	return cmd
+}
+
+func newPromptRenderCmd(stdout io.Writer) *cobra.Command {
+	dirFlag := "."
+	promptModeFlag := string(mdtest.PromptModePath)
+	var varFlags []string
+	cmd := &cobra.Command{
+		Use:   "render <test.md>...",
+		Short: "Print the exact prompt the agent receives for each test case",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			vars, err := parseVars(varFlags)
+			if err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			promptMode, err := mdtest.ParsePromptMode(promptModeFlag)
+			if err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			previews, err := mdtest.PreviewPrompts(mdtest.Config{
+				Root:       dirFlag,
+				Files:      append([]string(nil), args...),
+				Vars:       vars,
+				PromptMode: promptMode,
+			}, mdtest.Dependencies{})
+			if err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			for i, preview := range previews {
+				if len(previews) > 1 {
+					if i > 0 {
+						_, _ = fmt.Fprintln(stdout)
+					}
+					_, _ = fmt.Fprintf(stdout, "==> %s <==\n", preview.ID)
+				}
+				_, _ = fmt.Fprint(stdout, preview.Prompt)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&dirFlag, "dir", "d", ".", "Suite root directory")
+	cmd.Flags().StringArrayVar(&varFlags, "var", nil, "Set a ${NAME} test variable as NAME=VALUE (repeatable)")
+	cmd.Flags().StringVar(&promptModeFlag, "prompt-mode", string(mdtest.PromptModePath), "How the agent receives the test: path or inline")
+	return cmd
+}
+
+func newRunCmd(lookPath mdtest.LookPathFunc, runSuite RunSuiteFunc) *cobra.Command {
+	agentFlag := string(mdtest.AgentAuto)
 	dirFlag := "."
 	interactiveFlag := false
 	dangerousFlag := false
+	orderFlag := string(mdtest.OrderLexical)
+	var seedFlag uint64
+	promptModeFlag := string(mdtest.PromptModePath)
+	strictLogFlag := false
+	runXFailFlag := false
+	logRepairsFlag := 0
+	jobsFlag := 1
+	formatFlag := string(mdtest.FormatText)
+	recordFlag := ""
+	replayFlag := ""
+	var varFlags []string
+	var reportFlags []string
 	cmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run markdown tests",
-		RunE: func(_ *cobra.Command, args []string) error {
-			mode, err := agent.ParseMode(agentFlag)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode, err := mdtest.ParseAgentMode(agentFlag)
+			if err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			if recordFlag != "" && replayFlag != "" {
+				return &ExitError{Code: ExitSetupError, Err: fmt.Errorf("--record and --replay cannot be used together")}
+			}
+			find := lookPath
+			if replayFlag != "" {
+				// A replay runs no agent, so its binary need not be installed.
+				find = func(file string) (string, error) { return file, nil }
+			}
+			resolved, err := mdtest.ResolveAgent(mode, find)
+			if err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			order, err := mdtest.ParseOrder(orderFlag)
+			if err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			if cmd.Flags().Changed("seed") && order != mdtest.OrderRandom {
+				return &ExitError{Code: ExitSetupError, Err: fmt.Errorf("--seed only applies to --order random, got --order %s", order)}
+			}
+			vars, err := parseVars(varFlags)
+			if err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			promptMode, err := mdtest.ParsePromptMode(promptModeFlag)
 			if err != nil {
 				return &ExitError{Code: ExitSetupError, Err: err}
 			}
-			resolved, err := agent.Resolve(mode, lookPath)
+			format, err := mdtest.ParseFormat(formatFlag)
 			if err != nil {
 				return &ExitError{Code: ExitSetupError, Err: err}
 			}
+			if logRepairsFlag < 0 {
+				return &ExitError{Code: ExitSetupError, Err: fmt.Errorf("--log-repairs must not be negative, got %d", logRepairsFlag)}
+			}
+			if jobsFlag < 1 {
+				return &ExitError{Code: ExitSetupError, Err: fmt.Errorf("--jobs must be at least 1, got %d", jobsFlag)}
+			}
+			reports := make([]mdtest.ReportSpec, 0, len(reportFlags))
+			for _, raw := range reportFlags {
+				spec, err := mdtest.ParseReportSpec(raw)
+				if err != nil {
+					return &ExitError{Code: ExitSetupError, Err: err}
+				}
+				reports = append(reports, spec)
+			}
 
-			suite, err := runSuite(context.Background(), run.Config{
+			cfg := mdtest.Config{
 				Root:                       dirFlag,
 				Files:                      append([]string(nil), args...),
 				Agent:                      resolved,
 				Interactive:                interactiveFlag,
 				DangerouslyAllowAllActions: dangerousFlag,
-			})
+				Order:                      order,
+				Seed:                       seedFlag,
+				Vars:                       vars,
+				PromptMode:                 promptMode,
+				StrictLog:                  strictLogFlag,
+				RunXFail:                   runXFailFlag,
+				RepairLimit:                logRepairsFlag,
+				Jobs:                       jobsFlag,
+				Format:                     format,
+				RecordDir:                  recordFlag,
+				ReplayDir:                  replayFlag,
+			}
+			out := cmd.OutOrStdout()
+			reporters := []mdtest.Reporter{progressReporter(out, cfg)}
+			files := mdtest.NewFileReporter(reports)
+			if len(reports) > 0 {
+				reporters = append(reporters, files)
+			}
+			var github *mdtest.GitHubReporter
+			if env, ok := mdtest.LookupGitHubEnv(os.LookupEnv); ok {
+				github = mdtest.NewGitHubReporter(annotationOutput(cmd, cfg), env)
+				reporters = append(reporters, github)
+			}
+
+			// Stop the run on Ctrl-C or SIGTERM. Canceling only stops the
+			// tests; teardown hooks still run before Run returns.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			suite, err := runSuite(ctx, cfg, reporters)
 			if err != nil {
-				var setupErr *run.SetupError
+				var setupErr *mdtest.SetupError
 				if errors.As(err, &setupErr) {
 					return &ExitError{Code: ExitSetupError, Err: setupErr}
 				}
 				return &ExitError{Code: ExitSetupError, Err: err}
 			}
+			if err := files.Err(); err != nil {
+				return &ExitError{Code: ExitSetupError, Err: err}
+			}
+			if github != nil && github.Err() != nil {
+				return &ExitError{Code: ExitSetupError, Err: github.Err()}
+			}
+			if suite.Errored > 0 {
+				return &ExitError{Code: ExitSetupError, Err: fmt.Errorf("%d test(s) had fixture errors", suite.Errored)}
+			}
 			if suite.Failed > 0 {
 				return &ExitError{Code: ExitFailed, Err: fmt.Errorf("%d test(s) failed", suite.Failed)}
 			}
+			if suite.XPassed > 0 {
+				return &ExitError{Code: ExitFailed, Err: fmt.Errorf("%d xfail test(s) unexpectedly passed", suite.XPassed)}
+			}
+			if suite.HooksFailed > 0 {
+				return &ExitError{Code: ExitFailed, Err: fmt.Errorf("%d hook(s) failed", suite.HooksFailed)}
+			}
 			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&agentFlag, "agent", "a", string(agent.AutoMode), "Agent mode: auto, claude, or codex")
+	cmd.Flags().StringVarP(&agentFlag, "agent", "a", string(mdtest.AgentAuto), "Agent mode: auto, claude, codex, or fake (scripted, for testing)")
 	cmd.Flags().StringVarP(&dirFlag, "dir", "d", ".", "Suite root directory")
 	cmd.Flags().BoolVarP(&interactiveFlag, "interactive", "i", false, "Run agent in interactive mode")
 	cmd.Flags().BoolVarP(&dangerousFlag, "dangerously-allow-all-actions", "A", false, "Disable agent safety approvals/sandboxing")
+	cmd.Flags().StringVar(&orderFlag, "order", string(mdtest.OrderLexical), "Test order: lexical, random, slowest-first, or failed-first")
+	cmd.Flags().Uint64Var(&seedFlag, "seed", 0, "Seed for --order random (0 picks a new seed); requires --order random")
+	cmd.Flags().StringArrayVar(&varFlags, "var", nil, "Set a ${NAME} test variable as NAME=VALUE (repeatable)")
+	cmd.Flags().StringVar(&promptModeFlag, "prompt-mode", string(mdtest.PromptModePath), "How the agent receives the test: path or inline")
+	cmd.Flags().BoolVar(&strictLogFlag, "strict-log", false, "Fail tests whose log breaks any rule of the log schema")
+	cmd.Flags().BoolVar(&runXFailFlag, "run-xfail", false, "Run tests marked skip: or xfail: as normal tests")
+	cmd.Flags().StringArrayVar(&reportFlags, "report", nil, "Write results as FORMAT=PATH, FORMAT one of junit, markdown or html (repeatable)")
+	cmd.Flags().StringVar(&formatFlag, "format", string(mdtest.FormatText), "Progress output: text, tap (TAP version 13), or json (one event per line)")
+	cmd.Flags().StringVar(&recordFlag, "record", "", "Save every agent invocation as a cassette in DIR")
+	cmd.Flags().StringVar(&replayFlag, "replay", "", "Play back the cassettes in DIR instead of running the agent")
+	cmd.Flags().IntVar(&logRepairsFlag, "log-repairs", 0, "Resume the agent up to N times to fix a missing or invalid log")
+	cmd.Flags().IntVarP(&jobsFlag, "jobs", "j", 1, "Run up to N independent tests at once")
 	return cmd
 }
 
+func parseVars(raw []string) (map[string]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(raw))
+	for _, entry := range raw {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid --var %q (expected NAME=VALUE)", entry)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+	return vars, nil
+}
+
+// annotationOutput picks where GitHub annotations go. The runner reads
+// workflow commands from stdout and stderr alike, so they stay out of TAP and
+// JSON streams, which must hold nothing else.
+func annotationOutput(cmd *cobra.Command, cfg mdtest.Config) io.Writer {
+	if cfg.Format == mdtest.FormatText {
+		return cmd.OutOrStdout()
+	}
+	return cmd.ErrOrStderr()
+}
+
+// progressReporter returns the reporter that shows the run on out in the
+// format of cfg.
+func progressReporter(out io.Writer, cfg mdtest.Config) mdtest.Reporter {
+	switch cfg.Format {
+	case mdtest.FormatTAP:
+		return mdtest.NewTAPReporter(out)
+	case mdtest.FormatJSON:
+		return mdtest.NewJSONReporter(out)
+	default:
+		return mdtest.NewConsoleReporter(out, consoleMode(out, cfg))
+	}
+}
+
+// consoleMode picks the progress UI only for a color terminal that the
+// agent does not take over in interactive mode.
+func consoleMode(out io.Writer, cfg mdtest.Config) mdtest.ConsoleMode {
+	f, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) || cfg.Interactive {
+		return mdtest.ConsolePlain
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return mdtest.ConsolePlain
+	}
+	return mdtest.ConsoleProgress
+}
+
 func DefaultLookPath(file string) (string, error) {
-	return exec.LookPath(file)
+	return mdtest.DefaultLookPath(file)
 }
 
 func defaultRunSuite(out io.Writer) RunSuiteFunc {
-	return func(ctx context.Context, cfg run.Config) (run.SuiteResult, error) {
-		deps := run.DefaultDependencies(out, func(ctx context.Context, req run.ExecRequest) (run.ExecResult, error) {
-			execResult, err := procexec.Run(ctx, procexec.Request{
-				RootAbs:     req.RootAbs,
-				Argv:        req.Argv,
-				Interactive: req.Interactive,
-			})
-			return run.ExecResult{ExitCode: execResult.ExitCode}, err
-		})
-		return run.Run(ctx, cfg, deps)
+	return func(ctx context.Context, cfg mdtest.Config, reporters []mdtest.Reporter) (mdtest.SuiteResult, error) {
+		deps := mdtest.DefaultDependencies(out)
+		if cfg.Format != mdtest.FormatText {
+			// TAP and JSON consumers read stdout, so agent output goes to
+			// stderr instead.
+			deps.Exec = mdtest.ProcessExec(os.Stderr)
+		}
+		if cfg.Agent == mdtest.Fake {
+			deps.Exec = mdtest.FakeExec
+		}
+		deps.Reporters = reporters
+		return mdtest.Run(ctx, cfg, deps)
 	}
 }
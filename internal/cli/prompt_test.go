@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/mdtest"
+)
+
+func TestExecutePromptRenderUsesConfiguredTemplate(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "mdtest.yaml"), "prompt: prompt.tmpl\nvars:\n  BASE_URL: http://localhost:3000\n")
+	writeFile(t, filepath.Join(root, "prompt.tmpl"), "Run {{.TestID}} (attempt {{.Attempt}}){{range .Vars}} {{.Name}}={{.Value}}{{end}}\n")
+	writeFile(t, filepath.Join(root, "login.test.md"), "Open ${BASE_URL}.\n")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := executeWithDeps(
+		[]string{"prompt", "render", "--dir", root, "--var", "BASE_URL=https://staging.example.com", "login.test.md"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "", exec.ErrNotFound },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	want := "Run login.test.md (attempt 1) BASE_URL=https://staging.example.com\n"
+	if stdout.String() != want {
+		t.Fatalf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestExecutePromptRenderSeparatesMatrixCases(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.test.md"), "---\nmatrix:\n  role: [admin, viewer]\n---\n")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := executeWithDeps(
+		[]string{"prompt", "render", "-d", root, "a.test.md"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "", exec.ErrNotFound },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	for _, want := range []string{"==> a.test.md[role=admin] <==", "==> a.test.md[role=viewer] <==", "- role: viewer"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("stdout missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestExecutePromptRenderReportsTemplateErrors(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "mdtest.yaml"), "prompt: prompt.tmpl\n")
+	writeFile(t, filepath.Join(root, "prompt.tmpl"), "{{.Unknown}}")
+	writeFile(t, filepath.Join(root, "a.test.md"), "")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := executeWithDeps(
+		[]string{"prompt", "render", "-d", root, "a.test.md"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "", exec.ErrNotFound },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2", code)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
@@ -3,13 +3,20 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
-	"github.com/PeronGH/mdtest-cli/internal/agent"
-	"github.com/PeronGH/mdtest-cli/internal/run"
+	"github.com/PeronGH/mdtest-cli/mdtest"
 )
 
 func TestExecuteRejectsInvalidAgentFlag(t *testing.T) {
@@ -21,7 +28,9 @@ func TestExecuteRejectsInvalidAgentFlag(t *testing.T) {
 		&stdout,
 		&stderr,
 		func(string) (string, error) { return "", exec.ErrNotFound },
-		func(context.Context, run.Config) (run.SuiteResult, error) { return run.SuiteResult{}, nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
 	)
 
 	if code != 2 {
@@ -35,7 +44,7 @@ func TestExecuteRejectsInvalidAgentFlag(t *testing.T) {
 func TestExecuteRunUsesDefaultAutoAndReturnsPassCode(t *testing.T) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
-	var gotCfg run.Config
+	var gotCfg mdtest.Config
 
 	code := executeWithDeps(
 		[]string{"run"},
@@ -47,18 +56,22 @@ func TestExecuteRunUsesDefaultAutoAndReturnsPassCode(t *testing.T) {
 			}
 			return "", exec.ErrNotFound
 		},
-		func(_ context.Context, cfg run.Config) (run.SuiteResult, error) {
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
 			gotCfg = cfg
-			return run.SuiteResult{Total: 2, Passed: 2, Failed: 0}, nil
+			return mdtest.SuiteResult{Total: 2, Passed: 2, Failed: 0}, nil
 		},
 	)
 
 	if code != 0 {
 		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
 	}
-	wantCfg := run.Config{
-		Root:  ".",
-		Agent: agent.ClaudeAgent,
+	wantCfg := mdtest.Config{
+		Root:       ".",
+		Agent:      mdtest.Claude,
+		Order:      mdtest.OrderLexical,
+		PromptMode: mdtest.PromptModePath,
+		Jobs:       1,
+		Format:     mdtest.FormatText,
 	}
 	if !reflect.DeepEqual(gotCfg, wantCfg) {
 		t.Fatalf("run config = %#v, want %#v", gotCfg, wantCfg)
@@ -74,7 +87,9 @@ func TestExecuteRunFailsSetupWhenRequestedAgentMissing(t *testing.T) {
 		&stdout,
 		&stderr,
 		func(string) (string, error) { return "", exec.ErrNotFound },
-		func(context.Context, run.Config) (run.SuiteResult, error) { return run.SuiteResult{}, nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
 	)
 
 	if code != 2 {
@@ -99,8 +114,8 @@ func TestExecuteRunReturnsFailureCodeWhenSuiteHasFailures(t *testing.T) {
 			}
 			return "", exec.ErrNotFound
 		},
-		func(context.Context, run.Config) (run.SuiteResult, error) {
-			return run.SuiteResult{Total: 3, Passed: 2, Failed: 1}, nil
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{Total: 3, Passed: 2, Failed: 1}, nil
 		},
 	)
 
@@ -123,8 +138,8 @@ func TestExecuteRunReturnsSetupCodeWhenRunnerErrors(t *testing.T) {
 			}
 			return "", exec.ErrNotFound
 		},
-		func(context.Context, run.Config) (run.SuiteResult, error) {
-			return run.SuiteResult{}, &run.SetupError{Err: errors.New("no tests")}
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, &mdtest.SetupError{Err: errors.New("no tests")}
 		},
 	)
 
@@ -136,7 +151,7 @@ func TestExecuteRunReturnsSetupCodeWhenRunnerErrors(t *testing.T) {
 func TestExecuteRunParsesLongFlags(t *testing.T) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
-	var gotCfg run.Config
+	var gotCfg mdtest.Config
 
 	code := executeWithDeps(
 		[]string{
@@ -156,9 +171,9 @@ func TestExecuteRunParsesLongFlags(t *testing.T) {
 			}
 			return "", exec.ErrNotFound
 		},
-		func(_ context.Context, cfg run.Config) (run.SuiteResult, error) {
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
 			gotCfg = cfg
-			return run.SuiteResult{Total: 1, Passed: 1, Failed: 0}, nil
+			return mdtest.SuiteResult{Total: 1, Passed: 1, Failed: 0}, nil
 		},
 	)
 
@@ -166,12 +181,16 @@ func TestExecuteRunParsesLongFlags(t *testing.T) {
 		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
 	}
 
-	wantCfg := run.Config{
+	wantCfg := mdtest.Config{
 		Root:                       "tests/smoke",
 		Files:                      []string{"a.test.md", "nested/b.test.md"},
-		Agent:                      agent.CodexAgent,
+		Agent:                      mdtest.Codex,
 		Interactive:                true,
 		DangerouslyAllowAllActions: true,
+		Order:                      mdtest.OrderLexical,
+		PromptMode:                 mdtest.PromptModePath,
+		Jobs:                       1,
+		Format:                     mdtest.FormatText,
 	}
 	if !reflect.DeepEqual(gotCfg, wantCfg) {
 		t.Fatalf("run config = %#v, want %#v", gotCfg, wantCfg)
@@ -181,7 +200,7 @@ func TestExecuteRunParsesLongFlags(t *testing.T) {
 func TestExecuteRunParsesShortFlags(t *testing.T) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
-	var gotCfg run.Config
+	var gotCfg mdtest.Config
 
 	code := executeWithDeps(
 		[]string{
@@ -201,9 +220,9 @@ func TestExecuteRunParsesShortFlags(t *testing.T) {
 			}
 			return "", exec.ErrNotFound
 		},
-		func(_ context.Context, cfg run.Config) (run.SuiteResult, error) {
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
 			gotCfg = cfg
-			return run.SuiteResult{Total: 1, Passed: 1, Failed: 0}, nil
+			return mdtest.SuiteResult{Total: 1, Passed: 1, Failed: 0}, nil
 		},
 	)
 
@@ -211,14 +230,661 @@ func TestExecuteRunParsesShortFlags(t *testing.T) {
 		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
 	}
 
-	wantCfg := run.Config{
+	wantCfg := mdtest.Config{
 		Root:                       "tests/smoke",
 		Files:                      []string{"a.test.md", "nested/b.test.md"},
-		Agent:                      agent.CodexAgent,
+		Agent:                      mdtest.Codex,
 		Interactive:                true,
 		DangerouslyAllowAllActions: true,
+		Order:                      mdtest.OrderLexical,
+		PromptMode:                 mdtest.PromptModePath,
+		Jobs:                       1,
+		Format:                     mdtest.FormatText,
 	}
 	if !reflect.DeepEqual(gotCfg, wantCfg) {
 		t.Fatalf("run config = %#v, want %#v", gotCfg, wantCfg)
 	}
 }
+
+func TestExecuteRunParsesOrderAndSeed(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--order", "random", "--seed", "42"},
+		&stdout,
+		&stderr,
+		func(file string) (string, error) {
+			if file == "claude" {
+				return "/usr/bin/claude", nil
+			}
+			return "", exec.ErrNotFound
+		},
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 1, Passed: 1, Failed: 0}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	if gotCfg.Order != mdtest.OrderRandom || gotCfg.Seed != 42 {
+		t.Fatalf("order/seed = %q/%d, want random/42", gotCfg.Order, gotCfg.Seed)
+	}
+}
+
+func TestExecuteRejectsSeedWithoutRandomOrder(t *testing.T) {
+	for _, args := range [][]string{
+		{"run", "--seed", "42"},
+		{"run", "--order", "failed-first", "--seed", "42"},
+	} {
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		ran := false
+
+		code := executeWithDeps(
+			args,
+			&stdout,
+			&stderr,
+			func(string) (string, error) { return "/usr/bin/claude", nil },
+			func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+				ran = true
+				return mdtest.SuiteResult{}, nil
+			},
+		)
+
+		if code != 2 || ran {
+			t.Fatalf("%v: exit code = %d, ran = %v, want 2 without running", args, code, ran)
+		}
+		if !strings.Contains(stderr.String(), "--seed only applies to --order random") {
+			t.Fatalf("%v: stderr = %q, want the seed error", args, stderr.String())
+		}
+	}
+}
+
+func TestExecuteRejectsInvalidOrderFlag(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := executeWithDeps(
+		[]string{"run", "--order", "sideways"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2", code)
+	}
+}
+
+func TestExecuteRunReturnsFailureCodeWhenHooksFail(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := executeWithDeps(
+		[]string{"run"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{Total: 1, Skipped: 1, HooksFailed: 1}, nil
+		},
+	)
+
+	if code != 1 {
+		t.Fatalf("Execute exit code = %d, want 1", code)
+	}
+}
+
+func TestExecuteRunReturnsSetupCodeWhenFixturesFail(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := executeWithDeps(
+		[]string{"run"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{Total: 2, Passed: 1, Errored: 1}, nil
+		},
+	)
+
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2", code)
+	}
+}
+
+func TestExecuteRunParsesVars(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--var", "BASE_URL=http://localhost:3000/?a=b", "--var", "USER=qa"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 1, Passed: 1}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	want := map[string]string{"BASE_URL": "http://localhost:3000/?a=b", "USER": "qa"}
+	if !reflect.DeepEqual(gotCfg.Vars, want) {
+		t.Fatalf("Vars = %#v, want %#v", gotCfg.Vars, want)
+	}
+}
+
+func TestExecuteRejectsMalformedVar(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := executeWithDeps(
+		[]string{"run", "--var", "BASE_URL"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2", code)
+	}
+}
+
+func TestExecuteRunParsesPromptMode(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--prompt-mode", "inline"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 1, Passed: 1}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	if gotCfg.PromptMode != mdtest.PromptModeInline {
+		t.Fatalf("PromptMode = %q, want inline", gotCfg.PromptMode)
+	}
+
+	code = executeWithDeps(
+		[]string{"run", "--prompt-mode", "stdin"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2 for invalid prompt mode", code)
+	}
+}
+
+func TestExecuteRunParsesStrictLog(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--strict-log"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 1, Passed: 1}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	if !gotCfg.StrictLog {
+		t.Fatal("StrictLog = false, want true")
+	}
+}
+
+func TestExecuteRunParsesLogRepairs(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--log-repairs", "2"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 1, Passed: 1}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	if gotCfg.RepairLimit != 2 {
+		t.Fatalf("RepairLimit = %d, want 2", gotCfg.RepairLimit)
+	}
+
+	code = executeWithDeps(
+		[]string{"run", "--log-repairs", "-1"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2 for negative repairs", code)
+	}
+}
+
+func TestExecuteRunParsesJobs(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--jobs", "4"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 1, Passed: 1}, nil
+		},
+	)
+
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	if gotCfg.Jobs != 4 {
+		t.Fatalf("Jobs = %d, want 4", gotCfg.Jobs)
+	}
+
+	code = executeWithDeps(
+		[]string{"run", "--jobs", "0"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2 for zero jobs", code)
+	}
+}
+
+func TestExecuteRunWritesJUnitReport(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	path := filepath.Join(t.TempDir(), "reports", "junit.xml")
+
+	code := executeWithDeps(
+		[]string{"run", "--report", "junit=" + path},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, _ mdtest.Config, reporters []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return finishSuite(reporters, mdtest.SuiteResult{
+				Total:   1,
+				Failed:  1,
+				Results: []mdtest.TestResult{{ID: "a.test.md", TestRel: "a.test.md", Status: mdtest.TestFail, Reason: "status=fail"}},
+			})
+		},
+	)
+
+	if code != 1 {
+		t.Fatalf("Execute exit code = %d, want 1; stderr=%q", code, stderr.String())
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(content), `<failure message="status=fail">`) {
+		t.Fatalf("report = %s, want failure", content)
+	}
+}
+
+func TestExecuteRejectsInvalidReportFlag(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := executeWithDeps(
+		[]string{"run", "--report", "junit"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			t.Fatal("runSuite called despite invalid --report")
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2", code)
+	}
+}
+
+func TestExecuteRunFailsOnUnexpectedPass(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--run-xfail"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 2, XFailed: 1, XPassed: 1}, nil
+		},
+	)
+
+	if code != 1 {
+		t.Fatalf("Execute exit code = %d, want 1", code)
+	}
+	if !gotCfg.RunXFail {
+		t.Fatal("RunXFail = false, want true")
+	}
+}
+
+func TestExecuteRunParsesFormat(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var gotCfg mdtest.Config
+
+	code := executeWithDeps(
+		[]string{"run", "--format", "tap"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, cfg mdtest.Config, _ []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			gotCfg = cfg
+			return mdtest.SuiteResult{Total: 1, Passed: 1}, nil
+		},
+	)
+	if code != 0 {
+		t.Fatalf("Execute exit code = %d, want 0; stderr=%q", code, stderr.String())
+	}
+	if gotCfg.Format != mdtest.FormatTAP {
+		t.Fatalf("Format = %q, want tap", gotCfg.Format)
+	}
+
+	code = executeWithDeps(
+		[]string{"run", "--format", "xml"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2 for unknown format", code)
+	}
+}
+
+func TestExecuteRunWritesGitHubAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	summaryPath := filepath.Join(dir, "summary.md")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_WORKSPACE", dir)
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryPath)
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := executeWithDeps(
+		[]string{"run"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(_ context.Context, _ mdtest.Config, reporters []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return finishSuite(reporters, mdtest.SuiteResult{
+				Total:   1,
+				Failed:  1,
+				RootAbs: dir,
+				Results: []mdtest.TestResult{{ID: "a.test.md", TestRel: "a.test.md", Status: mdtest.TestFail, Reason: "status=fail"}},
+			})
+		},
+	)
+
+	if code != 1 {
+		t.Fatalf("Execute exit code = %d, want 1; stderr=%q", code, stderr.String())
+	}
+	if want := "::error file=a.test.md,title=a.test.md%3A fail::status=fail\n"; !strings.HasSuffix(stdout.String(), want) {
+		t.Fatalf("stdout = %q, want %q", stdout.String(), want)
+	}
+	summary, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("read job summary: %v", err)
+	}
+	if !strings.Contains(string(summary), "| ❌ fail | `a.test.md` |") {
+		t.Fatalf("job summary = %q", summary)
+	}
+}
+
+func TestExecuteRunKeepsGitHubAnnotationsOutOfMachineFormats(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "bad.test.md"), []byte("---\nfake:\n  status: fail\n  reason: wrong total\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_WORKSPACE", root)
+	t.Setenv("GITHUB_STEP_SUMMARY", filepath.Join(t.TempDir(), "summary.md"))
+
+	for _, format := range []string{"tap", "json"} {
+		t.Run(format, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			code := Execute(
+				[]string{"run", "--agent", "fake", "-d", root, "--format", format},
+				&stdout,
+				&stderr,
+				func(string) (string, error) { return "", exec.ErrNotFound },
+			)
+
+			if code != 1 {
+				t.Fatalf("Execute exit code = %d, want 1 (stderr %q)", code, stderr.String())
+			}
+			if strings.Contains(stdout.String(), "::error") {
+				t.Fatalf("stdout = %q, want no annotations", stdout.String())
+			}
+			if !strings.Contains(stderr.String(), "::error file=bad.test.md,") {
+				t.Fatalf("stderr = %q, want the annotation", stderr.String())
+			}
+			switch format {
+			case "tap":
+				if !strings.HasPrefix(stdout.String(), "TAP version 13\n") {
+					t.Fatalf("stdout = %q, want TAP", stdout.String())
+				}
+			case "json":
+				for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+					if !json.Valid([]byte(line)) {
+						t.Fatalf("stdout line %q is not JSON", line)
+					}
+				}
+			}
+		})
+	}
+}
+
+// finishSuite stands in for the end of mdtest.Run: it sends suite to reporters.
+func finishSuite(reporters []mdtest.Reporter, suite mdtest.SuiteResult) (mdtest.SuiteResult, error) {
+	for _, r := range reporters {
+		r.SuiteFinished(suite)
+	}
+	return suite, nil
+}
+
+func TestExecuteRunWithFakeAgentRunsFullPipeline(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "ok.test.md"), []byte("# OK\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "bad.test.md"), []byte("---\nfake:\n  status: fail\n  reason: wrong total\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := Execute(
+		[]string{"run", "--agent", "fake", "-d", root, "--format", "tap"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "", exec.ErrNotFound },
+	)
+
+	if code != 1 {
+		t.Fatalf("Execute exit code = %d, want 1 (stderr %q)", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "not ok 1 - bad.test.md # status=fail: wrong total") || !strings.Contains(out, "ok 2 - ok.test.md") {
+		t.Fatalf("stdout = %q, want TAP for both tests", out)
+	}
+}
+
+func TestExecuteRunRecordsAndReplaysCassettes(t *testing.T) {
+	root := t.TempDir()
+	cassettes := filepath.Join(t.TempDir(), "cassettes")
+	if err := os.WriteFile(filepath.Join(root, "bad.test.md"), []byte("---\nfake:\n  status: fail\n  reason: wrong total\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	notFound := func(string) (string, error) { return "", exec.ErrNotFound }
+
+	var recorded, stderr bytes.Buffer
+	code := Execute([]string{"run", "--agent", "fake", "-d", root, "--format", "tap", "--record", cassettes}, &recorded, &stderr, notFound)
+	if code != 1 {
+		t.Fatalf("record exit code = %d, want 1 (stderr %q)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(cassettes, "bad.test.md.1.json")); err != nil {
+		t.Fatalf("cassette: %v", err)
+	}
+
+	var replayed bytes.Buffer
+	stderr.Reset()
+	code = Execute([]string{"run", "--agent", "fake", "-d", root, "--format", "tap", "--replay", cassettes}, &replayed, &stderr, notFound)
+	if code != 1 {
+		t.Fatalf("replay exit code = %d, want 1 (stderr %q)", code, stderr.String())
+	}
+	// Log paths are timestamped per run; everything else must match.
+	withoutLogs := func(tap string) string {
+		var lines []string
+		for _, line := range strings.Split(tap, "\n") {
+			if !strings.HasPrefix(line, "  log: ") {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	if withoutLogs(replayed.String()) != withoutLogs(recorded.String()) {
+		t.Fatalf("replayed TAP = %q, want the recorded run's %q", replayed.String(), recorded.String())
+	}
+
+	// Replaying with another agent changes the argv, so it is refused.
+	stderr.Reset()
+	code = Execute([]string{"run", "--agent", "claude", "-d", root, "--format", "tap", "--replay", cassettes}, io.Discard, &stderr, notFound)
+	if code != 2 || !strings.Contains(stderr.String(), "different argv") {
+		t.Fatalf("replay with claude = %d, %q, want a setup error about the argv", code, stderr.String())
+	}
+}
+
+func TestExecuteRunTearsDownWhenInterrupted(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"_teardown.md": "# Clean up\n",
+		"slow.test.md": "---\nfake:\n  delay: 1m\n---\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Keep SIGINT from reaching the default handler should it arrive after
+	// the run stopped listening.
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, os.Interrupt)
+	defer signal.Stop(guard)
+
+	go func() {
+		// The log directory appears right before the agent starts.
+		for {
+			if _, err := os.Stat(filepath.Join(root, "slow.logs")); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	}()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	done := make(chan int, 1)
+	go func() {
+		done <- Execute(
+			[]string{"run", "--agent", "fake", "-d", root},
+			&stdout,
+			&stderr,
+			func(string) (string, error) { return "", exec.ErrNotFound },
+		)
+	}()
+	var code int
+	select {
+	case code = <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("run did not stop after SIGINT")
+	}
+
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2; stderr=%q", code, stderr.String())
+	}
+	logs, err := filepath.Glob(filepath.Join(root, "_teardown.logs", "*.log.md"))
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("teardown logs = %#v, %v, want the teardown to have run", logs, err)
+	}
+}
+
+func TestExecuteRejectsRecordWithReplay(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := executeWithDeps(
+		[]string{"run", "--record", "a", "--replay", "b"},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "/usr/bin/claude", nil },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			t.Fatal("runSuite called, want flag validation to fail first")
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 2 || !strings.Contains(stderr.String(), "--record and --replay") {
+		t.Fatalf("Execute = %d, %q, want setup error about --record and --replay", code, stderr.String())
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/mdtest"
+)
+
+func TestExecuteValidateLogReportsDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.log.md")
+	bad := filepath.Join(dir, "bad.log.md")
+	writeFile(t, good, "---\nstatus: pass\n---\nChecked out.\n")
+	writeFile(t, bad, "---\nstatus: maybe\n---\nChecked out.\n")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := executeWithDeps(
+		[]string{"validate-log", good, bad},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "", exec.ErrNotFound },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 1 {
+		t.Fatalf("Execute exit code = %d, want 1; stderr=%q", code, stderr.String())
+	}
+	want := good + ": ok\n" + bad + ":2: status must be pass or fail, got \"maybe\"\n"
+	if stdout.String() != want {
+		t.Fatalf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestExecuteValidateLogMissingFileIsSetupError(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := executeWithDeps(
+		[]string{"validate-log", filepath.Join(t.TempDir(), "none.log.md")},
+		&stdout,
+		&stderr,
+		func(string) (string, error) { return "", exec.ErrNotFound },
+		func(context.Context, mdtest.Config, []mdtest.Reporter) (mdtest.SuiteResult, error) {
+			return mdtest.SuiteResult{}, nil
+		},
+	)
+
+	if code != 2 {
+		t.Fatalf("Execute exit code = %d, want 2", code)
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// FileName is the per-directory config file. Settings apply to tests in
+// that directory and below.
+const FileName = "mdtest.yaml"
+
+// Dir is the content of one mdtest.yaml.
+type Dir struct {
+	// Path is the suite-relative directory the file was read from.
+	Path   string              `yaml:"-"`
+	Before testfile.StringList `yaml:"before"`
+	After  testfile.StringList `yaml:"after"`
+	Vars   map[string]string   `yaml:"vars"`
+	// Prompt is a text/template file, relative to this directory, that
+	// replaces the built-in agent prompt.
+	Prompt string `yaml:"prompt"`
+	// Context lists Markdown files, relative to this directory, whose
+	// contents are added to every prompt below it.
+	Context testfile.StringList `yaml:"context"`
+}
+
+// Stack holds the configs from the suite root down to one directory.
+type Stack []Dir
+
+// Before returns shell fixtures to run before a test, outermost first.
+func (s Stack) Before() []string {
+	var commands []string
+	for _, dir := range s {
+		commands = append(commands, dir.Before...)
+	}
+	return commands
+}
+
+// After returns shell fixtures to run after a test, innermost first.
+func (s Stack) After() []string {
+	var commands []string
+	for i := len(s) - 1; i >= 0; i-- {
+		commands = append(commands, s[i].After...)
+	}
+	return commands
+}
+
+// Vars merges variables from the root down; deeper directories win.
+func (s Stack) Vars() map[string]string {
+	vars := make(map[string]string)
+	for _, dir := range s {
+		for name, value := range dir.Vars {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// PromptTemplate returns the absolute path of the deepest configured prompt
+// template, or "" when the built-in prompt applies.
+func (s Stack) PromptTemplate(rootAbs string) string {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i].Prompt == "" {
+			continue
+		}
+		if filepath.IsAbs(s[i].Prompt) {
+			return filepath.Clean(s[i].Prompt)
+		}
+		return filepath.Join(rootAbs, filepath.FromSlash(s[i].Path), filepath.FromSlash(s[i].Prompt))
+	}
+	return ""
+}
+
+// Load reads every mdtest.yaml from rootAbs down to the suite-relative
+// directory dirRel. Missing files contribute an empty Dir.
+func Load(rootAbs string, dirRel string) (Stack, error) {
+	dirs := []string{"."}
+	if dirRel != "." && dirRel != "" {
+		var chain []string
+		for dir := path.Clean(dirRel); dir != "."; dir = path.Dir(dir) {
+			chain = append([]string{dir}, chain...)
+		}
+		dirs = append(dirs, chain...)
+	}
+
+	stack := make(Stack, 0, len(dirs))
+	for _, dir := range dirs {
+		cfgRel := path.Join(dir, FileName)
+		cfg, err := readDir(filepath.Join(rootAbs, filepath.FromSlash(cfgRel)))
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", cfgRel, err)
+		}
+		cfg.Path = dir
+		stack = append(stack, cfg)
+	}
+	return stack, nil
+}
+
+func readDir(path string) (Dir, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Dir{}, nil
+	}
+	if err != nil {
+		return Dir{}, err
+	}
+
+	var cfg Dir
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Dir{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadStacksConfigsFromRoot(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, filepath.Join(root, FileName), "before: root-up\nafter: root-down\n")
+	writeConfig(t, filepath.Join(root, "a", "b", FileName), "before: [b-up]\nafter: [b-down]\n")
+	writeConfig(t, filepath.Join(root, "a", FileName), "")
+
+	stack, err := Load(root, "a/b")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(stack) != 3 {
+		t.Fatalf("stack length = %d, want 3", len(stack))
+	}
+	if got, want := stack.Before(), []string{"root-up", "b-up"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Before = %#v, want %#v", got, want)
+	}
+	if got, want := stack.After(), []string{"b-down", "root-down"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("After = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, filepath.Join(root, FileName), "befor: typo\n")
+
+	if _, err := Load(root, "."); err == nil {
+		t.Fatal("Load returned nil error, want unknown key failure")
+	}
+}
+
+func writeConfig(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestStackVarsDeeperDirectoriesWin(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, filepath.Join(root, FileName), "vars:\n  BASE_URL: http://localhost:3000\n  USER: admin\n")
+	writeConfig(t, filepath.Join(root, "staging", FileName), "vars:\n  BASE_URL: https://staging.example.com\n")
+
+	stack, err := Load(root, "staging")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	want := map[string]string{"BASE_URL": "https://staging.example.com", "USER": "admin"}
+	if got := stack.Vars(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Vars = %#v, want %#v", got, want)
+	}
+}
+
+func TestStackPromptTemplateResolvesDeepestRelativeToItsDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, filepath.Join(root, FileName), "prompt: prompts/default.tmpl\n")
+	writeConfig(t, filepath.Join(root, "ui", FileName), "prompt: ui.tmpl\n")
+	writeConfig(t, filepath.Join(root, "ui", "admin", FileName), "vars: {A: b}\n")
+
+	stack, err := Load(root, "ui/admin")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if got, want := stack.PromptTemplate(root), filepath.Join(root, "ui", "ui.tmpl"); got != want {
+		t.Fatalf("PromptTemplate = %q, want %q", got, want)
+	}
+
+	stack, err = Load(root, "api")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if got, want := stack.PromptTemplate(root), filepath.Join(root, "prompts", "default.tmpl"); got != want {
+		t.Fatalf("PromptTemplate = %q, want %q", got, want)
+	}
+}
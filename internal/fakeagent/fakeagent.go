@@ -0,0 +1,264 @@
+// Package fakeagent is a scripted stand-in for a coding agent. It writes the
+// log a real agent would, following a script, so that suites, reporters and
+// CI wiring can be exercised offline and deterministically.
+//
+// The script for a test comes from a <name>.fake.yaml file next to the
+// test, or else from the fake: key of the test's front matter:
+//
+//	fake:
+//	  status: fail
+//	  reason: coupon discount was wrong
+//	  delay: 2s
+//	  output: "applying coupon SAVE10\n"
+//	  exit-code: 0
+//
+// A list scripts one attempt per entry, the last entry repeating, which
+// is how log repairs are tested:
+//
+//	fake:
+//	  - log: missing
+//	  - status: pass
+//
+// Without a script the fake agent passes.
+package fakeagent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// LogMode selects what kind of log an attempt leaves behind.
+type LogMode string
+
+const (
+	// LogOK writes a log that follows the schema.
+	LogOK LogMode = "ok"
+	// LogMissing writes no log.
+	LogMissing LogMode = "missing"
+	// LogInvalid writes a log whose status cannot be parsed.
+	LogInvalid LogMode = "invalid"
+	// LogNoBody writes valid front matter without a body, which only
+	// --strict-log rejects.
+	LogNoBody LogMode = "no-body"
+)
+
+// Attempt scripts one invocation of the fake agent.
+type Attempt struct {
+	// Status is the verdict in the log; empty means pass.
+	Status logs.Status `yaml:"status"`
+	Reason string      `yaml:"reason"`
+	// ExitCode is the exit code the invocation reports.
+	ExitCode int `yaml:"exit-code"`
+	// Delay is how long the invocation takes.
+	Delay time.Duration `yaml:"delay"`
+	// Output is printed as the agent's output.
+	Output string `yaml:"output"`
+	// Log selects the kind of log; empty means ok.
+	Log   LogMode `yaml:"log"`
+	Steps []Step  `yaml:"steps"`
+	// Cases defaults to every case of a multi-case file, with Status.
+	Cases    []Step            `yaml:"cases"`
+	Observed map[string]string `yaml:"observed"`
+}
+
+// Step is one step or case verdict in the log.
+type Step struct {
+	Name   string      `yaml:"name"`
+	Status logs.Status `yaml:"status"`
+	Reason string      `yaml:"reason,omitempty"`
+}
+
+// Script holds one attempt per invocation; the last one repeats. In YAML it
+// is a single attempt or a list of them.
+type Script []Attempt
+
+func (s *Script) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var attempts []Attempt
+		if err := node.Decode(&attempts); err != nil {
+			return err
+		}
+		*s = attempts
+		return nil
+	}
+	var attempt Attempt
+	if err := node.Decode(&attempt); err != nil {
+		return err
+	}
+	*s = Script{attempt}
+	return nil
+}
+
+// attempt returns the script for the 1-based attempt n.
+func (s Script) attempt(n int) Attempt {
+	if len(s) == 0 {
+		return Attempt{}
+	}
+	if n > len(s) {
+		n = len(s)
+	}
+	if n < 1 {
+		n = 1
+	}
+	return s[n-1]
+}
+
+// ScriptPath returns the fixture file that scripts the test at testAbs:
+// login.test.md is scripted by login.fake.yaml.
+func ScriptPath(testAbs string) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(testAbs, ".md"), ".test")
+	return base + ".fake.yaml"
+}
+
+// Exec runs the fake agent for req. It is a run.ExecFunc.
+func Exec(ctx context.Context, req run.ExecRequest) (run.ExecResult, error) {
+	file, err := testfile.Read(req.TestAbs)
+	if err != nil {
+		return run.ExecResult{}, fmt.Errorf("fake agent: %w", err)
+	}
+	script, err := loadScript(req.TestAbs, file)
+	if err != nil {
+		return run.ExecResult{}, fmt.Errorf("fake agent: %w", err)
+	}
+	attempt := script.attempt(req.Attempt)
+	switch attempt.Status {
+	case "", logs.StatusPass, logs.StatusFail:
+	default:
+		return run.ExecResult{}, fmt.Errorf("fake agent: %s: status must be pass or fail, got %q", req.ID, attempt.Status)
+	}
+
+	out := io.Discard
+	if req.Output != nil {
+		out = req.Output
+	}
+	if req.Transcript != nil {
+		out = io.MultiWriter(out, req.Transcript)
+	}
+	if attempt.Output != "" {
+		_, _ = io.WriteString(out, attempt.Output)
+	}
+
+	if attempt.Delay > 0 {
+		timer := time.NewTimer(attempt.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return run.ExecResult{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	content, err := renderLog(req, file, attempt)
+	if err != nil {
+		return run.ExecResult{}, fmt.Errorf("fake agent: %s: %w", req.ID, err)
+	}
+	if content != nil {
+		if err := os.WriteFile(req.LogAbs, content, 0o644); err != nil {
+			return run.ExecResult{}, fmt.Errorf("fake agent: %w", err)
+		}
+	}
+	return run.ExecResult{ExitCode: attempt.ExitCode}, nil
+}
+
+// loadScript reads the fixture file next to the test, or else the fake: key
+// of its front matter.
+func loadScript(testAbs string, file testfile.File) (Script, error) {
+	var script Script
+	content, err := os.ReadFile(ScriptPath(testAbs))
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(content, &script); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(ScriptPath(testAbs)), err)
+		}
+		return script, nil
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	raw, ok := file.FrontMatter["fake"]
+	if !ok {
+		return nil, nil
+	}
+	encoded, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(encoded, &script); err != nil {
+		return nil, fmt.Errorf("front matter fake: %w", err)
+	}
+	return script, nil
+}
+
+type logFrontMatter struct {
+	Status   logs.Status       `yaml:"status"`
+	Reason   string            `yaml:"reason,omitempty"`
+	Steps    []Step            `yaml:"steps,omitempty"`
+	Cases    []Step            `yaml:"cases,omitempty"`
+	Observed map[string]string `yaml:"observed,omitempty"`
+}
+
+// renderLog returns the log attempt leaves behind, or nil for none.
+func renderLog(req run.ExecRequest, file testfile.File, attempt Attempt) ([]byte, error) {
+	status := attempt.Status
+	if status == "" {
+		status = logs.StatusPass
+	}
+	switch attempt.Log {
+	case LogMissing:
+		return nil, nil
+	case LogInvalid:
+		return []byte("---\nstatus: unknown\n---\n\nThe fake agent wrote an invalid log.\n"), nil
+	case "", LogOK, LogNoBody:
+	default:
+		return nil, fmt.Errorf("log must be ok, missing, invalid or no-body, got %q", attempt.Log)
+	}
+
+	front := logFrontMatter{
+		Status:   status,
+		Reason:   attempt.Reason,
+		Steps:    attempt.Steps,
+		Cases:    attempt.Cases,
+		Observed: attempt.Observed,
+	}
+	if len(front.Cases) == 0 {
+		names, err := file.Meta.Cases.Resolve(file.Body)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			front.Cases = append(front.Cases, Step{Name: name, Status: status, Reason: attempt.Reason})
+		}
+	}
+	encoded, err := yaml.Marshal(front)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.Write(encoded)
+	b.WriteString("---\n")
+	if attempt.Log == LogNoBody {
+		return []byte(b.String()), nil
+	}
+	fmt.Fprintf(&b, "\n# %s\n\nThe fake agent ran attempt %d and reported %s.\n", req.ID, req.Attempt, status)
+	for _, step := range front.Steps {
+		fmt.Fprintf(&b, "\n## %s\n\n%s", step.Name, step.Status)
+		if step.Reason != "" {
+			fmt.Fprintf(&b, ": %s", step.Reason)
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
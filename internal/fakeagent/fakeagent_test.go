@@ -0,0 +1,155 @@
+package fakeagent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+}
+
+func runSuite(t *testing.T, root string, cfg run.Config) run.SuiteResult {
+	t.Helper()
+	cfg.Root = root
+	cfg.Agent = agent.FakeAgent
+	deps := run.DefaultDependencies(&strings.Builder{}, Exec)
+	suite, err := run.Run(context.Background(), cfg, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	return suite
+}
+
+func TestExecFollowsFrontMatterScripts(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "default.test.md"), "# Passes\n")
+	mustWriteFile(t, filepath.Join(root, "fail.test.md"), `---
+fake:
+  status: fail
+  reason: coupon discount was wrong
+  exit-code: 3
+  steps:
+    - name: apply coupon
+      status: fail
+      reason: total unchanged
+---
+# Coupon
+`)
+	mustWriteFile(t, filepath.Join(root, "invalid.test.md"), "---\nfake:\n  log: invalid\n---\n")
+	mustWriteFile(t, filepath.Join(root, "missing.test.md"), "---\nfake:\n  log: missing\n---\n")
+	mustWriteFile(t, filepath.Join(root, "cases.test.md"), "---\ncases: sections\nfake:\n  status: fail\n  reason: broken\n---\n## Guest\n## Admin\n")
+
+	suite := runSuite(t, root, run.Config{})
+
+	got := map[string]run.TestResult{}
+	for _, result := range suite.Results {
+		got[result.ID] = result
+	}
+	if got["default.test.md"].Status != run.TestPass {
+		t.Fatalf("default = %#v, want pass", got["default.test.md"])
+	}
+	fail := got["fail.test.md"]
+	if fail.Status != run.TestFail || fail.Reason != "status=fail: coupon discount was wrong (agent exit code 3)" {
+		t.Fatalf("fail = %q %q", fail.Status, fail.Reason)
+	}
+	if len(fail.Steps) != 1 || fail.Steps[0].Reason != "total unchanged" {
+		t.Fatalf("fail steps = %#v", fail.Steps)
+	}
+	if diags, err := logs.Validate(fail.LogAbs); err != nil || len(diags) != 0 {
+		t.Fatalf("Validate(fail log) = %#v, %v, want a schema-valid log", diags, err)
+	}
+	if got["invalid.test.md"].LogState != run.LogInvalid {
+		t.Fatalf("invalid LogState = %q, want invalid", got["invalid.test.md"].LogState)
+	}
+	if got["missing.test.md"].LogState != run.LogMissing {
+		t.Fatalf("missing LogState = %q, want missing", got["missing.test.md"].LogState)
+	}
+	cases := got["cases.test.md"].Cases
+	if len(cases) != 2 || cases[0].Name != "Guest" || cases[1].Status != run.TestFail || cases[1].Reason != "broken" {
+		t.Fatalf("cases = %#v", cases)
+	}
+}
+
+func TestExecScriptsEachAttemptForLogRepairs(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "repair.test.md"), "---\nfake:\n  - log: missing\n  - log: invalid\n  - status: pass\n---\n")
+
+	suite := runSuite(t, root, run.Config{RepairLimit: 2})
+
+	result := suite.Results[0]
+	if result.Status != run.TestPass || result.Repairs != 2 {
+		t.Fatalf("result = %q after %d repairs, want pass after 2", result.Status, result.Repairs)
+	}
+}
+
+func TestExecPrefersFixtureFileAndWritesOutput(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "login.test.md"), "---\nfake:\n  status: fail\n---\n")
+	mustWriteFile(t, filepath.Join(root, "login.fake.yaml"), "status: pass\noutput: \"logged in\\n\"\n")
+
+	suite := runSuite(t, root, run.Config{})
+
+	result := suite.Results[0]
+	if result.Status != run.TestPass {
+		t.Fatalf("status = %q, want the fixture file's pass", result.Status)
+	}
+	transcript, err := os.ReadFile(result.TranscriptAbs)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	if !strings.Contains(string(transcript), "logged in\n") {
+		t.Fatalf("transcript = %q, want the scripted output", transcript)
+	}
+}
+
+func TestExecNoBodyFailsOnlyStrictLog(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "lax.test.md"), "---\nfake:\n  log: no-body\n---\n")
+
+	if got := runSuite(t, root, run.Config{}).Results[0].Status; got != run.TestPass {
+		t.Fatalf("status = %q, want pass without --strict-log", got)
+	}
+	if got := runSuite(t, root, run.Config{StrictLog: true}).Results[0].Status; got != run.TestFail {
+		t.Fatalf("status = %q, want fail with --strict-log", got)
+	}
+}
+
+func TestExecDelayHonorsContext(t *testing.T) {
+	root := t.TempDir()
+	testAbs := filepath.Join(root, "slow.test.md")
+	mustWriteFile(t, testAbs, "---\nfake:\n  delay: 1h\n---\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := Exec(ctx, run.ExecRequest{RootAbs: root, ID: "slow.test.md", TestAbs: testAbs, LogAbs: filepath.Join(root, "slow.log.md"), Attempt: 1})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Exec error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestExecRejectsUnknownStatus(t *testing.T) {
+	root := t.TempDir()
+	testAbs := filepath.Join(root, "bad.test.md")
+	mustWriteFile(t, testAbs, "---\nfake:\n  status: maybe\n---\n")
+
+	_, err := Exec(context.Background(), run.ExecRequest{RootAbs: root, ID: "bad.test.md", TestAbs: testAbs, Attempt: 1})
+	if err == nil || !strings.Contains(err.Error(), `status must be pass or fail, got "maybe"`) {
+		t.Fatalf("Exec error = %v, want status error", err)
+	}
+}
@@ -1,11 +1,12 @@
 package logs
 
 import (
-	"bufio"
-	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,47 +18,94 @@ const (
 	StatusFail Status = "fail"
 )
 
+// Log is the front matter of an agent log. Only Status is required; the
+// other fields are optional evidence the prompt asks the agent to record:
+//
+//	status: pass|fail
+//	reason: why the test failed
+//	steps:
+//	  - name: add to cart
+//	    status: pass
+//	  - name: apply coupon
+//	    status: fail
+//	    reason: expected 20% discount, got 15%
+//	observed:
+//	  discount: 15%
+//	artifacts:
+//	  - screenshots/coupon.png
+//	duration: 1m30s
+//
+// Files that declare cases report one entry per case under cases:, in the
+// same shape as steps.
+type Log struct {
+	Status Status
+	Reason string
+	Steps  []Step
+	Cases  []Step
+	// Observed maps names to values the agent saw while testing.
+	Observed map[string]string
+	// Artifacts lists files the agent produced, relative to the log.
+	Artifacts []string
+	// Duration is how long the agent reports testing took.
+	Duration time.Duration
+}
+
+// Step is the verdict of one test step or case.
+type Step struct {
+	Name   string
+	Status Status
+	Reason string
+}
+
 func ParseStatus(path string) (Status, error) {
-	content, err := os.ReadFile(path)
+	log, err := ParseLog(path)
 	if err != nil {
-		return "", fmt.Errorf("read log: %w", err)
+		return "", err
 	}
+	return log.Status, nil
+}
 
-	scanner := bufio.NewScanner(bytes.NewReader(content))
-	if !scanner.Scan() {
-		return "", fmt.Errorf("missing front matter")
-	}
-	if strings.TrimSuffix(scanner.Text(), "\r") != "---" {
-		return "", fmt.Errorf("front matter must start at byte 0 with ---")
+// ParseLog reads the front matter of the log at path. Optional fields with
+// an unexpected shape are ignored so that a log with a valid status is
+// never rejected for its evidence.
+func ParseLog(path string) (Log, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Log{}, fmt.Errorf("read log: %w", err)
 	}
 
-	yamlLines := make([]string, 0)
-	closed := false
-	for scanner.Scan() {
-		line := strings.TrimSuffix(scanner.Text(), "\r")
-		if line == "---" {
-			closed = true
-			break
-		}
-		yamlLines = append(yamlLines, line)
-	}
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("scan log: %w", err)
-	}
-	if !closed {
-		return "", fmt.Errorf("missing closing front matter delimiter")
+	fm, problem := splitFrontMatter(content)
+	if problem != nil {
+		return Log{}, errors.New(problem.Message)
 	}
 
 	parsed := make(map[string]any)
-	if err := yaml.Unmarshal([]byte(strings.Join(yamlLines, "\n")), &parsed); err != nil {
-		return "", fmt.Errorf("parse yaml: %w", err)
+	if err := yaml.Unmarshal([]byte(fm.YAML), &parsed); err != nil {
+		return Log{}, fmt.Errorf("parse yaml: %w", err)
 	}
 
 	raw, ok := parsed["status"]
 	if !ok {
-		return "", fmt.Errorf("missing status key")
+		return Log{}, fmt.Errorf("missing status key")
 	}
+	status, err := parseStatusValue(raw)
+	if err != nil {
+		return Log{}, err
+	}
+
+	log := Log{
+		Status:    status,
+		Reason:    scalarString(parsed["reason"]),
+		Steps:     parseSteps(parsed["steps"]),
+		Cases:     parseSteps(parsed["cases"]),
+		Observed:  parseObserved(parsed["observed"]),
+		Artifacts: parseArtifacts(parsed["artifacts"]),
+		Duration:  parseDuration(parsed["duration"]),
+	}
+	return log, nil
+}
 
+func parseStatusValue(raw any) (Status, error) {
 	normalized := strings.ToLower(strings.TrimSpace(fmt.Sprint(raw)))
 	switch normalized {
 	case string(StatusPass):
@@ -68,3 +116,115 @@ func ParseStatus(path string) (Status, error) {
 		return "", fmt.Errorf("invalid status value %q", normalized)
 	}
 }
+
+func parseSteps(raw any) []Step {
+	items, _ := raw.([]any)
+	var steps []Step
+	for _, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		step := Step{
+			Name:   scalarString(fields["name"]),
+			Reason: scalarString(fields["reason"]),
+		}
+		if status, err := parseStatusValue(fields["status"]); err == nil {
+			step.Status = status
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func parseObserved(raw any) map[string]string {
+	fields, _ := raw.(map[string]any)
+	if len(fields) == 0 {
+		return nil
+	}
+	observed := make(map[string]string, len(fields))
+	for name, value := range fields {
+		observed[name] = scalarString(value)
+	}
+	return observed
+}
+
+func parseArtifacts(raw any) []string {
+	switch value := raw.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var artifacts []string
+		for _, item := range value {
+			if s := scalarString(item); s != "" {
+				artifacts = append(artifacts, s)
+			}
+		}
+		return artifacts
+	default:
+		return nil
+	}
+}
+
+// parseDuration accepts a Go duration string ("1m30s") or a number of
+// seconds.
+func parseDuration(raw any) time.Duration {
+	switch value := raw.(type) {
+	case int:
+		return time.Duration(value) * time.Second
+	case float64:
+		return time.Duration(value * float64(time.Second))
+	case string:
+		if d, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			return d
+		}
+		if seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+func scalarString(raw any) string {
+	switch value := raw.(type) {
+	case nil, map[string]any, []any:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// frontMatter is a log split at its front matter delimiters.
+type frontMatter struct {
+	YAML string
+	Body string
+	// EndLine is the 1-based line of the closing delimiter.
+	EndLine int
+}
+
+func splitFrontMatter(content []byte) (frontMatter, *Diagnostic) {
+	if len(content) == 0 {
+		return frontMatter{}, &Diagnostic{Line: 1, Message: "missing front matter"}
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if strings.TrimRight(lines[0], "\r\n") != "---" {
+		return frontMatter{}, &Diagnostic{Line: 1, Message: "front matter must start at byte 0 with ---"}
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n") != "---" {
+			continue
+		}
+		yamlLines := make([]string, 0, i-1)
+		for _, line := range lines[1:i] {
+			yamlLines = append(yamlLines, strings.TrimRight(line, "\r\n"))
+		}
+		return frontMatter{
+			YAML:    strings.Join(yamlLines, "\n"),
+			Body:    strings.Join(lines[i+1:], ""),
+			EndLine: i + 1,
+		}, nil
+	}
+	return frontMatter{}, &Diagnostic{Line: len(lines), Message: "missing closing front matter delimiter"}
+}
@@ -3,7 +3,9 @@ package logs
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParseStatusSuccessCases(t *testing.T) {
@@ -76,3 +78,71 @@ func writeLog(t *testing.T, content string) string {
 	}
 	return path
 }
+
+func TestParseLogReadsEvidence(t *testing.T) {
+	path := writeLog(t, `---
+status: fail
+reason: coupon discount was wrong
+steps:
+  - name: add to cart
+    status: pass
+  - name: apply coupon
+    status: FAIL
+    reason: expected 20%, got 15%
+observed:
+  discount: 15%
+  items: 1
+artifacts:
+  - screenshots/coupon.png
+duration: 1m30s
+cases:
+  - name: guest
+    status: pass
+---
+body
+`)
+
+	got, err := ParseLog(path)
+	if err != nil {
+		t.Fatalf("ParseLog returned error: %v", err)
+	}
+	want := Log{
+		Status: StatusFail,
+		Reason: "coupon discount was wrong",
+		Steps: []Step{
+			{Name: "add to cart", Status: StatusPass},
+			{Name: "apply coupon", Status: StatusFail, Reason: "expected 20%, got 15%"},
+		},
+		Cases:     []Step{{Name: "guest", Status: StatusPass}},
+		Observed:  map[string]string{"discount": "15%", "items": "1"},
+		Artifacts: []string{"screenshots/coupon.png"},
+		Duration:  90 * time.Second,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLog = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseLogIgnoresMalformedEvidence(t *testing.T) {
+	path := writeLog(t, "---\nstatus: pass\nsteps: nope\nobserved: [1]\nduration: soon\n---\n")
+
+	got, err := ParseLog(path)
+	if err != nil {
+		t.Fatalf("ParseLog returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, Log{Status: StatusPass}) {
+		t.Fatalf("ParseLog = %#v, want status only", got)
+	}
+}
+
+func TestParseLogAcceptsDurationInSeconds(t *testing.T) {
+	path := writeLog(t, "---\nstatus: pass\nduration: 2.5\n---\n")
+
+	got, err := ParseLog(path)
+	if err != nil {
+		t.Fatalf("ParseLog returned error: %v", err)
+	}
+	if got.Duration != 2500*time.Millisecond {
+		t.Fatalf("Duration = %v, want 2.5s", got.Duration)
+	}
+}
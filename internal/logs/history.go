@@ -0,0 +1,100 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// PreviousRun describes the most recent recorded log of a test.
+type PreviousRun struct {
+	LogAbs    string
+	StartedAt time.Time
+	Duration  time.Duration
+	Status    Status
+	// Reason is the reason the log gives for its status.
+	Reason   string
+	ParseErr error
+}
+
+// Failed reports whether the previous run did not end with status pass.
+func (p PreviousRun) Failed() bool {
+	return p.ParseErr != nil || p.Status != StatusPass
+}
+
+var logNamePattern = regexp.MustCompile(`^((\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}Z)(?:-(\d+))?)\.(?:log\.md|transcript\.txt)$`)
+
+// LatestRun returns the newest log written by NextLogPath for testAbs.
+// Duration is the duration the log reports, or else is estimated from the
+// timestamp in the log name to the log's modification time. A run that
+// left a transcript but no log counts too: its ParseErr is the error
+// reading the missing log, so it is a failed run. The boolean result is
+// false when no run was recorded.
+func LatestRun(testAbs string) (PreviousRun, bool, error) {
+	logDir, _, err := NextLogPath(testAbs, time.Time{})
+	if err != nil {
+		return PreviousRun{}, false, err
+	}
+
+	entries, err := os.ReadDir(logDir)
+	if os.IsNotExist(err) {
+		return PreviousRun{}, false, nil
+	}
+	if err != nil {
+		return PreviousRun{}, false, err
+	}
+
+	var (
+		bestStem  string
+		bestStart time.Time
+		bestSeq   = -1
+	)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := logNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		start, err := time.Parse("2006-01-02T15-04-05Z", match[2])
+		if err != nil {
+			continue
+		}
+		seq := 0
+		if match[3] != "" {
+			seq, _ = strconv.Atoi(match[3])
+		}
+		if bestStem == "" || start.After(bestStart) || (start.Equal(bestStart) && seq > bestSeq) {
+			bestStem, bestStart, bestSeq = match[1], start, seq
+		}
+	}
+	if bestStem == "" {
+		return PreviousRun{}, false, nil
+	}
+
+	logAbs := filepath.Join(logDir, bestStem+".log.md")
+	info, err := os.Stat(logAbs)
+	if os.IsNotExist(err) {
+		info, err = os.Stat(filepath.Join(logDir, bestStem+".transcript.txt"))
+	}
+	if err != nil {
+		return PreviousRun{}, false, err
+	}
+
+	prev := PreviousRun{
+		LogAbs:    logAbs,
+		StartedAt: bestStart,
+	}
+	if elapsed := info.ModTime().Sub(bestStart); elapsed > 0 {
+		prev.Duration = elapsed
+	}
+	log, err := ParseLog(logAbs)
+	prev.Status, prev.Reason, prev.ParseErr = log.Status, log.Reason, err
+	if log.Duration > 0 {
+		prev.Duration = log.Duration
+	}
+	return prev, true, nil
+}
@@ -0,0 +1,96 @@
+package logs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLatestRunPicksNewestLogAndEstimatesDuration(t *testing.T) {
+	root := t.TempDir()
+	testAbs := filepath.Join(root, "checkout.test.md")
+	logDir := filepath.Join(root, "checkout.logs")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	older := filepath.Join(logDir, "2026-02-10T14-30-00Z.log.md")
+	newer := filepath.Join(logDir, "2026-02-10T14-30-00Z-1.log.md")
+	for path, content := range map[string]string{
+		older: "---\nstatus: pass\n---\n",
+		newer: "---\nstatus: fail\nreason: total was wrong\n---\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	started := time.Date(2026, time.February, 10, 14, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(newer, started.Add(90*time.Second), started.Add(90*time.Second)); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	prev, ok, err := LatestRun(testAbs)
+	if err != nil {
+		t.Fatalf("LatestRun returned error: %v", err)
+	}
+	if !ok {
+		t.Fatal("LatestRun found no log, want newest")
+	}
+	if prev.LogAbs != newer {
+		t.Fatalf("LogAbs = %q, want %q", prev.LogAbs, newer)
+	}
+	if prev.Duration != 90*time.Second {
+		t.Fatalf("Duration = %v, want 1m30s", prev.Duration)
+	}
+	if !prev.Failed() {
+		t.Fatal("Failed() = false, want true for status fail")
+	}
+	if prev.Reason != "total was wrong" {
+		t.Fatalf("Reason = %q, want the log's reason", prev.Reason)
+	}
+}
+
+func TestLatestRunCountsTranscriptWithoutLogAsFailed(t *testing.T) {
+	root := t.TempDir()
+	testAbs := filepath.Join(root, "checkout.test.md")
+	logDir := filepath.Join(root, "checkout.logs")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for name, content := range map[string]string{
+		"2026-02-10T14-30-00Z.log.md":         "---\nstatus: pass\n---\n",
+		"2026-02-10T14-30-00Z.transcript.txt": "done\n",
+		"2026-02-10T15-00-00Z.transcript.txt": "crashed\n",
+	} {
+		if err := os.WriteFile(filepath.Join(logDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	prev, ok, err := LatestRun(testAbs)
+	if err != nil {
+		t.Fatalf("LatestRun returned error: %v", err)
+	}
+	if !ok {
+		t.Fatal("LatestRun found no run, want the one without a log")
+	}
+	if want := filepath.Join(logDir, "2026-02-10T15-00-00Z.log.md"); prev.LogAbs != want {
+		t.Fatalf("LogAbs = %q, want %q", prev.LogAbs, want)
+	}
+	if !errors.Is(prev.ParseErr, fs.ErrNotExist) || !prev.Failed() {
+		t.Fatalf("PreviousRun = %#v, want a failed run with a missing log", prev)
+	}
+}
+
+func TestLatestRunWithoutLogs(t *testing.T) {
+	_, ok, err := LatestRun(filepath.Join(t.TempDir(), "checkout.test.md"))
+	if err != nil {
+		t.Fatalf("LatestRun returned error: %v", err)
+	}
+	if ok {
+		t.Fatal("LatestRun found a log, want none")
+	}
+}
@@ -9,13 +9,19 @@ import (
 )
 
 // NextLogPath returns the sibling log directory and next non-colliding log path.
+// Besides *.test.md files it accepts other Markdown files run by the agent,
+// such as _setup.md hooks, whose logs go to <name>.logs.
 func NextLogPath(testAbs string, at time.Time) (string, string, error) {
 	base := filepath.Base(testAbs)
-	if !strings.HasSuffix(base, ".test.md") {
-		return "", "", fmt.Errorf("test path %q does not end with .test.md", testAbs)
+	var stem string
+	switch {
+	case strings.HasSuffix(base, ".test.md"):
+		stem = strings.TrimSuffix(base, ".test.md")
+	case strings.HasSuffix(base, ".md"):
+		stem = strings.TrimSuffix(base, ".md")
+	default:
+		return "", "", fmt.Errorf("test path %q does not end with .md", testAbs)
 	}
-
-	stem := strings.TrimSuffix(base, ".test.md")
 	logDir := filepath.Join(filepath.Dir(testAbs), stem+".logs")
 	stamp := at.UTC().Format("2006-01-02T15-04-05Z")
 
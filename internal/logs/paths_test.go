@@ -55,3 +55,20 @@ func TestNextLogPathAddsCollisionSuffix(t *testing.T) {
 		t.Fatalf("second log filename = %q, want %q", filepath.Base(second), "2026-02-10T14-30-00Z-1.log.md")
 	}
 }
+
+func TestNextLogPathAcceptsHookMarkdown(t *testing.T) {
+	root := t.TempDir()
+	at := time.Date(2026, time.February, 10, 14, 30, 0, 0, time.UTC)
+
+	logDir, _, err := NextLogPath(filepath.Join(root, "_setup.md"), at)
+	if err != nil {
+		t.Fatalf("NextLogPath returned error: %v", err)
+	}
+	if logDir != filepath.Join(root, "_setup.logs") {
+		t.Fatalf("logDir = %q, want _setup.logs", logDir)
+	}
+
+	if _, _, err := NextLogPath(filepath.Join(root, "notes.txt"), at); err == nil {
+		t.Fatal("NextLogPath accepted a non-Markdown file")
+	}
+}
@@ -0,0 +1,215 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic is one way a log breaks the schema documented on Log.
+type Diagnostic struct {
+	// Line is 1-based within the log file.
+	Line    int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+}
+
+// Validate checks the log at path strictly against the schema: front matter
+// at byte 0 with a valid status, correctly typed optional keys, and a
+// non-empty body that describes every step. The error is only for logs that
+// cannot be read.
+func Validate(path string) ([]Diagnostic, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read log: %w", err)
+	}
+	return ValidateContent(content), nil
+}
+
+// ValidateContent is Validate for a log already in memory.
+func ValidateContent(content []byte) []Diagnostic {
+	fm, problem := splitFrontMatter(content)
+	if problem != nil {
+		return []Diagnostic{*problem}
+	}
+
+	v := &validator{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(fm.YAML), &doc); err != nil {
+		return []Diagnostic{{Line: yamlErrorLine(err), Message: fmt.Sprintf("parse yaml: %v", err)}}
+	}
+	if len(doc.Content) == 0 {
+		v.add(1, "missing required key status")
+		return v.diags
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		v.add(root.Line+1, "front matter must be a mapping")
+		return v.diags
+	}
+
+	var steps []*yaml.Node
+	hasStatus := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "status":
+			hasStatus = true
+			v.status(value, "status")
+		case "reason":
+			v.scalar(value, "reason")
+		case "steps", "cases":
+			steps = append(steps, v.steps(value, key.Value)...)
+		case "observed":
+			v.observed(value)
+		case "artifacts":
+			v.artifacts(value)
+		case "duration":
+			v.duration(value)
+		}
+	}
+	if !hasStatus {
+		v.add(1, "missing required key status")
+	}
+
+	if strings.TrimSpace(fm.Body) == "" {
+		v.add(fm.EndLine, "log body is empty; describe what was done after the front matter")
+		return v.sorted()
+	}
+	body := strings.ToLower(fm.Body)
+	for _, name := range steps {
+		if !strings.Contains(body, strings.ToLower(name.Value)) {
+			v.add(name.Line+1, fmt.Sprintf("%q is not described in the log body", name.Value))
+		}
+	}
+	return v.sorted()
+}
+
+type validator struct {
+	diags []Diagnostic
+}
+
+// add records a problem. Lines of yaml nodes are offset by one for the
+// opening delimiter before the call.
+func (v *validator) add(line int, message string) {
+	v.diags = append(v.diags, Diagnostic{Line: line, Message: message})
+}
+
+func (v *validator) sorted() []Diagnostic {
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		return v.diags[i].Line < v.diags[j].Line
+	})
+	return v.diags
+}
+
+func (v *validator) scalar(node *yaml.Node, key string) bool {
+	if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		v.add(node.Line+1, fmt.Sprintf("%s must be a string", key))
+		return false
+	}
+	return true
+}
+
+func (v *validator) status(node *yaml.Node, key string) {
+	if !v.scalar(node, key) {
+		return
+	}
+	if _, err := parseStatusValue(node.Value); err != nil {
+		v.add(node.Line+1, fmt.Sprintf("%s must be pass or fail, got %q", key, node.Value))
+	}
+}
+
+// steps validates a steps or cases list and returns the name nodes of its
+// entries.
+func (v *validator) steps(node *yaml.Node, key string) []*yaml.Node {
+	if node.Kind != yaml.SequenceNode {
+		v.add(node.Line+1, key+" must be a list")
+		return nil
+	}
+	var names []*yaml.Node
+	for i, item := range node.Content {
+		label := fmt.Sprintf("%s[%d]", key, i)
+		if item.Kind != yaml.MappingNode {
+			v.add(item.Line+1, label+" must be a mapping with name and status")
+			continue
+		}
+		var name, status *yaml.Node
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			key, value := item.Content[j], item.Content[j+1]
+			switch key.Value {
+			case "name":
+				name = value
+			case "status":
+				status = value
+			case "reason":
+				v.scalar(value, label+".reason")
+			default:
+				v.add(key.Line+1, fmt.Sprintf("unknown key %s.%s", label, key.Value))
+			}
+		}
+		if name == nil {
+			v.add(item.Line+1, label+" is missing name")
+		} else if v.scalar(name, label+".name") {
+			if strings.TrimSpace(name.Value) == "" {
+				v.add(name.Line+1, label+".name must not be empty")
+			} else {
+				names = append(names, name)
+			}
+		}
+		if status == nil {
+			v.add(item.Line+1, label+" is missing status")
+		} else {
+			v.status(status, label+".status")
+		}
+	}
+	return names
+}
+
+func (v *validator) observed(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		v.add(node.Line+1, "observed must be a mapping")
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		v.scalar(node.Content[i+1], "observed."+node.Content[i].Value)
+	}
+}
+
+func (v *validator) artifacts(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		v.add(node.Line+1, "artifacts must be a list of paths")
+		return
+	}
+	for i, item := range node.Content {
+		v.scalar(item, fmt.Sprintf("artifacts[%d]", i))
+	}
+}
+
+func (v *validator) duration(node *yaml.Node) {
+	if !v.scalar(node, "duration") {
+		return
+	}
+	if parseDuration(node.Value) == 0 && strings.Trim(node.Value, "0.s ") != "" {
+		v.add(node.Line+1, fmt.Sprintf("duration must be like 1m30s or a number of seconds, got %q", node.Value))
+	}
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorLine maps the line in a yaml error to a line of the log file.
+func yamlErrorLine(err error) int {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 2
+	}
+	line, _ := strconv.Atoi(match[1])
+	return line + 1
+}
@@ -0,0 +1,79 @@
+package logs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateContentAcceptsSchemaLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "status only", content: "---\nstatus: pass\n---\nClicked through checkout.\n"},
+		{
+			name: "full schema",
+			content: "---\nstatus: fail\nreason: wrong discount\nsteps:\n  - name: Add to cart\n    status: pass\n  - name: Apply coupon\n    status: fail\n    reason: got 15%\n" +
+				"observed:\n  discount: 15%\nartifacts:\n  - coupon.png\nduration: 90\n---\n## add to cart\nok\n## apply coupon\nwrong\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateContent([]byte(tt.content)); len(got) != 0 {
+				t.Fatalf("ValidateContent = %#v, want no diagnostics", got)
+			}
+		})
+	}
+}
+
+func TestValidateContentReportsLineNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Diagnostic
+	}{
+		{
+			name:    "not at byte zero",
+			content: "\n---\nstatus: pass\n---\n",
+			want:    []Diagnostic{{Line: 1, Message: "front matter must start at byte 0 with ---"}},
+		},
+		{
+			name:    "unclosed",
+			content: "---\nstatus: pass\nbody\n",
+			want:    []Diagnostic{{Line: 4, Message: "missing closing front matter delimiter"}},
+		},
+		{
+			name:    "bad status and empty body",
+			content: "---\nreason: x\nstatus: maybe\n---\n\n",
+			want: []Diagnostic{
+				{Line: 3, Message: `status must be pass or fail, got "maybe"`},
+				{Line: 4, Message: "log body is empty; describe what was done after the front matter"},
+			},
+		},
+		{
+			name:    "missing status",
+			content: "---\nreason: x\n---\nbody\n",
+			want:    []Diagnostic{{Line: 1, Message: "missing required key status"}},
+		},
+		{
+			name:    "step problems",
+			content: "---\nstatus: pass\nsteps:\n  - name: login\n  - status: pass\nartifacts: shot.png\n---\nbody\n",
+			want: []Diagnostic{
+				{Line: 4, Message: "steps[0] is missing status"},
+				{Line: 4, Message: `"login" is not described in the log body`},
+				{Line: 5, Message: "steps[1] is missing name"},
+				{Line: 6, Message: "artifacts must be a list of paths"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateContent([]byte(tt.content))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ValidateContent = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
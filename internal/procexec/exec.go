@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"sync"
 
 	"github.com/PeronGH/mdtest-cli/internal/ptyexec"
 )
@@ -14,6 +16,12 @@ type Request struct {
 	RootAbs     string
 	Argv        []string
 	Interactive bool
+	// Transcript, when set, receives a copy of stdout and stderr.
+	Transcript io.Writer
+	// Stdout and Stderr receive the agent's output in batch mode; nil means
+	// os.Stdout and os.Stderr.
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 type Result struct {
@@ -51,8 +59,9 @@ func runWithDeps(ctx context.Context, req Request, deps runtimeDeps) (Result, er
 
 func runPTY(ctx context.Context, req Request) (Result, error) {
 	res, err := ptyexec.Run(ctx, ptyexec.Request{
-		RootAbs: req.RootAbs,
-		Argv:    req.Argv,
+		RootAbs:    req.RootAbs,
+		Argv:       req.Argv,
+		Transcript: req.Transcript,
 	})
 	if err != nil {
 		return Result{}, err
@@ -63,9 +72,57 @@ func runPTY(ctx context.Context, req Request) (Result, error) {
 func runBatch(ctx context.Context, req Request) (Result, error) {
 	cmd := exec.CommandContext(ctx, req.Argv[0], req.Argv[1:]...)
 	cmd.Dir = req.RootAbs
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if req.Stdout != nil {
+		stdout = req.Stdout
+	}
+	if req.Stderr != nil {
+		stderr = req.Stderr
+	}
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if req.Transcript != nil {
+		// A shared writer keeps the streams interleaved as they were printed.
+		transcript := &syncWriter{w: req.Transcript}
+		cmd.Stdout = io.MultiWriter(stdout, transcript)
+		cmd.Stderr = io.MultiWriter(stderr, transcript)
+	}
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return Result{ExitCode: exitErr.ExitCode()}, nil
+		}
+		return Result{}, err
+	}
+	return Result{ExitCode: 0}, nil
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+type ShellRequest struct {
+	Dir     string
+	Command string
+	Output  io.Writer
+}
+
+// RunShell runs a shell command with stdout and stderr sent to req.Output.
+func RunShell(ctx context.Context, req ShellRequest) (Result, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", req.Command)
+	cmd.Dir = req.Dir
+	cmd.Stdout = req.Output
+	cmd.Stderr = req.Output
 
 	err := cmd.Run()
 	if err != nil {
@@ -3,6 +3,7 @@ package procexec
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -103,3 +104,42 @@ func TestRunWithDepsRejectsEmptyArgv(t *testing.T) {
 		t.Fatal("runWithDeps returned nil error, want failure")
 	}
 }
+
+func TestRunShellCapturesOutputAndExitCode(t *testing.T) {
+	dir := t.TempDir()
+	var output strings.Builder
+
+	got, err := RunShell(context.Background(), ShellRequest{
+		Dir:     dir,
+		Command: "pwd; echo oops >&2; exit 3",
+		Output:  &output,
+	})
+	if err != nil {
+		t.Fatalf("RunShell returned error: %v", err)
+	}
+	if got.ExitCode != 3 {
+		t.Fatalf("ExitCode = %d, want 3", got.ExitCode)
+	}
+	if !strings.Contains(output.String(), dir) || !strings.Contains(output.String(), "oops") {
+		t.Fatalf("output = %q, want working directory and stderr", output.String())
+	}
+}
+
+func TestRunBatchCopiesOutputToTranscript(t *testing.T) {
+	var transcript strings.Builder
+
+	got, err := runBatch(context.Background(), Request{
+		RootAbs:    t.TempDir(),
+		Argv:       []string{"sh", "-c", "echo out; echo err >&2"},
+		Transcript: &transcript,
+	})
+	if err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+	if got.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d, want 0", got.ExitCode)
+	}
+	if !strings.Contains(transcript.String(), "out\n") || !strings.Contains(transcript.String(), "err\n") {
+		t.Fatalf("transcript = %q, want stdout and stderr", transcript.String())
+	}
+}
@@ -1,11 +1,167 @@
 package prompt
 
-import "fmt"
-
-func Render(testAbs string, logAbs string) string {
-	return fmt.Sprintf(
-		"Execute the test file step by step.\nRead the test from this exact absolute path: %s\nWrite the output log to this exact absolute path: %s\nThe output must begin with YAML front matter containing status: pass|fail.\n",
-		testAbs,
-		logAbs,
-	)
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// ContextFile is project context shared by every test below its directory.
+type ContextFile struct {
+	// Path is suite-relative.
+	Path    string
+	Content string
+}
+
+// Input is the data model of prompt templates. Custom templates refer to the
+// fields directly, e.g. {{.TestAbs}} or {{range .Vars}}{{.Name}}{{end}}.
+type Input struct {
+	// TestAbs is the file the agent should read. With TestBody set it is the
+	// original test file, used to resolve relative paths.
+	TestAbs string
+	// TestBody is the expanded test Markdown embedded in the prompt instead
+	// of being read from TestAbs. It is empty in path mode.
+	TestBody string
+	// ContentHash identifies TestBody when it is set.
+	ContentHash string
+	// LogAbs is where the agent must write its log.
+	LogAbs string
+	// SourceAbs is the original test file when TestAbs is a rendered copy.
+	SourceAbs string
+	// TestID is the suite-relative test path, with matrix parameters if any.
+	TestID string
+	// FrontMatter holds the test's raw front matter keys.
+	FrontMatter map[string]any
+	// Params holds the matrix parameters of the case, if any.
+	Params []testfile.Param
+	// Vars holds the variables substituted into the test body.
+	Vars []testfile.Param
+	// Attempt is the 1-based number of this agent invocation for the case.
+	Attempt int
+	// Cases names the cases of a multi-case file, to be reported separately.
+	Cases []string
+	// Capabilities lists what the test requires (front matter requires).
+	Capabilities []string
+	// PreviousFailure explains why the latest previous run failed, if it did.
+	PreviousFailure string
+	// Context holds project context files, from the suite root down to the
+	// test's directory.
+	Context []ContextFile
+}
+
+// DefaultTemplate is the built-in prompt.
+const DefaultTemplate = `Execute the test file step by step.
+{{- if .TestBody}}
+The test is embedded below between <test> tags; do not read it from disk. Resolve relative paths against the directory of {{.TestAbs}}.
+{{- else}}
+Read the test from this exact absolute path: {{.TestAbs}}
+{{- end}}
+Write the output log to this exact absolute path: {{.LogAbs}}
+The output must begin with YAML front matter containing status: pass|fail.
+The front matter should also record, where they apply:
+- reason: why the test failed
+- steps: one entry per step with name, status (pass|fail) and reason
+- observed: a mapping of the values you observed
+- artifacts: files you produced (screenshots, dumps), relative to the log
+- duration: how long testing took, e.g. 1m30s
+{{- if .ContentHash}}
+Also record content_hash: {{.ContentHash}} in the log front matter.
+{{- end}}
+{{- if .SourceAbs}}
+That file was rendered from {{.SourceAbs}}; resolve relative paths against the original file's directory.
+{{- end}}
+{{- if .Params}}
+Run this test case with these parameters:
+{{- range .Params}}
+- {{.Name}}: {{.Value}}
+{{- end}}
+{{- end}}
+{{- if .Vars}}
+These variables were substituted into the test. Record them under a vars: mapping in the log front matter:
+{{- range .Vars}}
+- {{.Name}}: {{.Value}}
+{{- end}}
+{{- end}}
+{{- if .Cases}}
+This file holds several cases. Report each under cases: in the log front matter, with name (exactly as given), status (pass|fail) and reason:
+{{- range .Cases}}
+- {{.}}
+{{- end}}
+{{- end}}
+{{- if .Context}}
+
+Project context for this test suite follows. Use it instead of rediscovering how to start, configure or reach the system under test.
+{{- range .Context}}
+
+<context path="{{.Path}}">
+{{.Content}}
+</context>
+{{- end}}
+{{- end}}
+{{- if .TestBody}}
+
+<test path="{{.TestAbs}}">
+{{.TestBody}}
+</test>
+{{- end}}
+`
+
+var defaultTemplate = template.Must(Parse("default", DefaultTemplate))
+
+// RepairInput is the data model of the log repair prompt.
+type RepairInput struct {
+	// LogAbs is the log the agent must fix.
+	LogAbs string
+	// Problem says why the log could not be used.
+	Problem string
+	// Attempt is the 1-based repair number for this log.
+	Attempt int
+}
+
+// RepairTemplate asks the agent, resumed in the session that ran the test,
+// to fix its log without testing again.
+const RepairTemplate = `The log you wrote for the test could not be used: {{.Problem}}
+Do not run the test again. Fix only the log file at this exact absolute path: {{.LogAbs}}
+It must begin at byte 0 with YAML front matter containing status: pass|fail, followed by a description of what you did.
+`
+
+var repairTemplate = template.Must(Parse("repair", RepairTemplate))
+
+// Parse compiles a prompt template. Unknown fields fail at render time.
+func Parse(name string, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// Render renders the built-in prompt.
+func Render(in Input) (string, error) {
+	return execute(defaultTemplate, in)
+}
+
+// RenderRepair renders the log repair prompt.
+func RenderRepair(in RepairInput) (string, error) {
+	return execute(repairTemplate, in)
+}
+
+// RenderFile renders the template stored at path.
+func RenderFile(path string, in Input) (string, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read prompt template: %w", err)
+	}
+	tmpl, err := Parse(path, string(text))
+	if err != nil {
+		return "", fmt.Errorf("parse prompt template: %w", err)
+	}
+	return execute(tmpl, in)
+}
+
+func execute(tmpl *template.Template, in any) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, in); err != nil {
+		return "", fmt.Errorf("render prompt template: %w", err)
+	}
+	return b.String(), nil
 }
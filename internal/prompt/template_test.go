@@ -1,16 +1,22 @@
 package prompt
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
 )
 
 func TestRenderIncludesRequiredFacts(t *testing.T) {
 	testAbs := filepath.Join("/tmp", "suite", "checkout.test.md")
 	logAbs := filepath.Join("/tmp", "suite", "checkout.logs", "2026-02-11T10-00-00Z.log.md")
 
-	got := Render(testAbs, logAbs)
+	got, err := Render(Input{TestAbs: testAbs, LogAbs: logAbs})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
 
 	checks := []string{
 		"step by step",
@@ -23,4 +29,173 @@ func TestRenderIncludesRequiredFacts(t *testing.T) {
 			t.Fatalf("Render output missing %q\nPrompt:\n%s", want, got)
 		}
 	}
+	if strings.Contains(got, "parameters") {
+		t.Fatalf("Render output mentions parameters without a matrix\nPrompt:\n%s", got)
+	}
+}
+
+func TestRenderListsMatrixParams(t *testing.T) {
+	got, err := Render(Input{
+		TestAbs: "/tmp/suite/checkout.test.md",
+		LogAbs:  "/tmp/suite/checkout[role=admin].logs/x.log.md",
+		Params: []testfile.Param{
+			{Name: "role", Value: "admin"},
+			{Name: "locale", Value: "ja"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	for _, want := range []string{"- role: admin\n", "- locale: ja\n"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Render output missing %q\nPrompt:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderShowsRenderedSourceAndVars(t *testing.T) {
+	got, err := Render(Input{
+		TestAbs:   "/tmp/suite/login.logs/x.input.md",
+		LogAbs:    "/tmp/suite/login.logs/x.log.md",
+		SourceAbs: "/tmp/suite/login.test.md",
+		Vars:      []testfile.Param{{Name: "BASE_URL", Value: "https://staging.example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"rendered from /tmp/suite/login.test.md",
+		"vars:",
+		"- BASE_URL: https://staging.example.com\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Render output missing %q\nPrompt:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderStartsWithLegacyPrompt(t *testing.T) {
+	got, err := Render(Input{TestAbs: "/s/a.test.md", LogAbs: "/s/a.logs/x.log.md"})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	want := "Execute the test file step by step.\nRead the test from this exact absolute path: /s/a.test.md\nWrite the output log to this exact absolute path: /s/a.logs/x.log.md\nThe output must begin with YAML front matter containing status: pass|fail.\n"
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("Render = %q, want prefix %q", got, want)
+	}
+	for _, key := range []string{"reason:", "steps:", "observed:", "artifacts:", "duration:"} {
+		if !strings.Contains(got, "- "+key) {
+			t.Fatalf("Render output does not ask for %q\nPrompt:\n%s", key, got)
+		}
+	}
+}
+
+func TestRenderFileUsesDataModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	if err := os.WriteFile(path, []byte("Test {{.TestID}} attempt {{.Attempt}}{{range .Capabilities}} +{{.}}{{end}}{{if .PreviousFailure}} (last: {{.PreviousFailure}}){{end}} owner={{.FrontMatter.owner}}"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := RenderFile(path, Input{
+		TestID:          "checkout.test.md",
+		Attempt:         2,
+		Capabilities:    []string{"browser"},
+		PreviousFailure: "status=fail",
+		FrontMatter:     map[string]any{"owner": "payments"},
+	})
+	if err != nil {
+		t.Fatalf("RenderFile returned error: %v", err)
+	}
+	want := "Test checkout.test.md attempt 2 +browser (last: status=fail) owner=payments"
+	if got != want {
+		t.Fatalf("RenderFile = %q, want %q", got, want)
+	}
+}
+
+func TestRenderFileReportsTemplateErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"syntax":        "{{.TestAbs",
+		"unknown field": "{{.Nope}}",
+	}
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".tmpl")
+			if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if _, err := RenderFile(path, Input{}); err == nil {
+				t.Fatal("RenderFile returned nil error, want failure")
+			}
+		})
+	}
+}
+
+func TestRenderIncludesProjectContextInOrder(t *testing.T) {
+	got, err := Render(Input{
+		TestAbs: "/s/ui/a.test.md",
+		LogAbs:  "/s/ui/a.logs/x.log.md",
+		Context: []ContextFile{
+			{Path: "MDTEST_CONTEXT.md", Content: "Start the app with `make dev`."},
+			{Path: "ui/MDTEST_CONTEXT.md", Content: "The UI runs on port 5173."},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	root := strings.Index(got, "<context path=\"MDTEST_CONTEXT.md\">\nStart the app with `make dev`.\n</context>")
+	ui := strings.Index(got, "<context path=\"ui/MDTEST_CONTEXT.md\">\nThe UI runs on port 5173.\n</context>")
+	if root < 0 || ui < 0 || root > ui {
+		t.Fatalf("Render output missing ordered context blocks\nPrompt:\n%s", got)
+	}
+}
+
+func TestRenderEmbedsInlineTestBody(t *testing.T) {
+	got, err := Render(Input{
+		TestAbs:     "/tmp/suite/login.test.md",
+		LogAbs:      "/tmp/suite/login.logs/x.log.md",
+		TestBody:    "Open the login page.",
+		ContentHash: "sha256:abc",
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"do not read it from disk",
+		"content_hash: sha256:abc",
+		"<test path=\"/tmp/suite/login.test.md\">\nOpen the login page.\n</test>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Render output missing %q\nPrompt:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Read the test from") {
+		t.Fatalf("inline prompt still asks to read the test file\nPrompt:\n%s", got)
+	}
+}
+
+func TestRenderRepairNamesLogAndProblem(t *testing.T) {
+	got, err := RenderRepair(RepairInput{LogAbs: "/s/a.logs/x.log.md", Problem: "agent produced no log", Attempt: 1})
+	if err != nil {
+		t.Fatalf("RenderRepair returned error: %v", err)
+	}
+	for _, want := range []string{"/s/a.logs/x.log.md", "agent produced no log", "Do not run the test again"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("RenderRepair output missing %q\nPrompt:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderListsCases(t *testing.T) {
+	got, err := Render(Input{TestAbs: "/s/a.test.md", LogAbs: "/s/a.log.md", Cases: []string{"add to cart", "apply coupon"}})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !strings.Contains(got, "under cases:") || !strings.Contains(got, "- add to cart\n- apply coupon\n") {
+		t.Fatalf("Render output does not list cases\nPrompt:\n%s", got)
+	}
 }
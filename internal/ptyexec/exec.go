@@ -17,6 +17,8 @@ import (
 type Request struct {
 	RootAbs string
 	Argv    []string
+	// Transcript, when set, receives a copy of the terminal output.
+	Transcript io.Writer
 }
 
 type Result struct {
@@ -94,8 +96,12 @@ func runWithConfig(ctx context.Context, req Request, cfg runtimeConfig) (res Res
 	}()
 
 	stdoutDone := make(chan struct{})
+	var stdout io.Writer = cfg.stdout
+	if req.Transcript != nil {
+		stdout = io.MultiWriter(cfg.stdout, req.Transcript)
+	}
 	go func() {
-		_, _ = cfg.copyStream(cfg.stdout, ptmx)
+		_, _ = cfg.copyStream(stdout, ptmx)
 		close(stdoutDone)
 	}()
 
@@ -0,0 +1,202 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+// GitHubEnv holds the GitHub Actions environment variables mdtest reads.
+type GitHubEnv struct {
+	// Workspace is GITHUB_WORKSPACE; annotation paths are relative to it.
+	Workspace string
+	// StepSummary is GITHUB_STEP_SUMMARY, the file the job summary is
+	// appended to. Empty skips the summary.
+	StepSummary string
+}
+
+// LookupGitHubEnv returns the GitHub Actions environment, and false when
+// GITHUB_ACTIONS is not "true".
+func LookupGitHubEnv(lookupEnv func(key string) (string, bool)) (GitHubEnv, bool) {
+	if value, _ := lookupEnv("GITHUB_ACTIONS"); value != "true" {
+		return GitHubEnv{}, false
+	}
+	workspace, _ := lookupEnv("GITHUB_WORKSPACE")
+	summary, _ := lookupEnv("GITHUB_STEP_SUMMARY")
+	return GitHubEnv{Workspace: workspace, StepSummary: summary}, true
+}
+
+// WriteGitHub writes an ::error workflow command to w for every failed test
+// and hook, and appends a Markdown job summary to env.StepSummary.
+func WriteGitHub(w io.Writer, env GitHubEnv, suite run.SuiteResult) error {
+	if err := WriteGitHubAnnotations(w, env.Workspace, suite); err != nil {
+		return err
+	}
+	if env.StepSummary == "" {
+		return nil
+	}
+	f, err := os.OpenFile(env.StepSummary, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("write job summary: %w", err)
+	}
+	var b strings.Builder
+	writeMarkdownBody(&b, suite, "")
+	if _, err := io.WriteString(f, b.String()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write job summary: %w", err)
+	}
+	return f.Close()
+}
+
+// WriteGitHubAnnotations writes ::error commands pointing at the .test.md
+// files of failed tests. A failed case or step gets its own annotation on
+// the line of the file that mentions it, when there is one.
+func WriteGitHubAnnotations(w io.Writer, workspace string, suite run.SuiteResult) error {
+	for _, result := range suite.Results {
+		switch result.Status {
+		case run.TestFail, run.TestError, run.TestXPass:
+		default:
+			continue
+		}
+		testAbs := filepath.Join(suite.RootAbs, result.TestRel)
+		file := annotationPath(workspace, testAbs)
+		content, _ := os.ReadFile(testAbs)
+
+		annotated := false
+		for _, c := range result.Cases {
+			status, reason := caseStatus(result, c)
+			if status != run.TestFail && status != run.TestError {
+				continue
+			}
+			if err := writeAnnotation(w, file, findLine(string(content), c.Name), fmt.Sprintf("%s: case %q failed", result.ID, c.Name), reason); err != nil {
+				return err
+			}
+			annotated = true
+		}
+		for _, step := range result.Steps {
+			if step.Status == logs.StatusPass {
+				continue
+			}
+			line := findLine(string(content), step.Name)
+			if line == 0 {
+				continue
+			}
+			message := step.Reason
+			if message == "" {
+				message = result.Reason
+			}
+			if err := writeAnnotation(w, file, line, fmt.Sprintf("%s: step %q failed", result.ID, step.Name), message); err != nil {
+				return err
+			}
+			annotated = true
+		}
+		if !annotated {
+			if err := writeAnnotation(w, file, 0, fmt.Sprintf("%s: %s", result.ID, result.Status), result.Reason); err != nil {
+				return err
+			}
+		}
+	}
+	for _, hook := range suite.Hooks {
+		if hook.Status == run.TestPass {
+			continue
+		}
+		file := annotationPath(workspace, filepath.Join(suite.RootAbs, hook.HookRel))
+		if err := writeAnnotation(w, file, 0, fmt.Sprintf("%s %s failed", hook.Kind, hook.HookRel), hook.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAnnotation(w io.Writer, file string, line int, title, message string) error {
+	props := "file=" + escapeProperty(file)
+	if line > 0 {
+		props += fmt.Sprintf(",line=%d", line)
+	}
+	props += ",title=" + escapeProperty(title)
+	_, err := fmt.Fprintf(w, "::error %s::%s\n", props, escapeData(message))
+	return err
+}
+
+// annotationPath makes path relative to the workspace, as GitHub expects.
+func annotationPath(workspace, path string) string {
+	if workspace != "" {
+		if rel, err := filepath.Rel(workspace, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// findLine returns the 1-based line of the test file that mentions text,
+// preferring headings and skipping front matter, or 0 when none does.
+func findLine(content, text string) int {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return 0
+	}
+	lines := strings.Split(content, "\n")
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+	for _, headingsOnly := range []bool{true, false} {
+		for i := start; i < len(lines); i++ {
+			line := strings.TrimSpace(lines[i])
+			if headingsOnly && !strings.HasPrefix(line, "#") {
+				continue
+			}
+			if strings.Contains(strings.ToLower(line), text) {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+var (
+	dataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeData(text string) string {
+	return dataEscaper.Replace(text)
+}
+
+func escapeProperty(text string) string {
+	return propertyEscaper.Replace(text)
+}
+
+// GitHubReporter is a run.Reporter that writes annotations to w and the job
+// summary when the suite finishes. Err returns the write error.
+type GitHubReporter struct {
+	run.BaseReporter
+	w   io.Writer
+	env GitHubEnv
+	err error
+}
+
+// NewGitHubReporter returns a GitHubReporter for env.
+func NewGitHubReporter(w io.Writer, env GitHubEnv) *GitHubReporter {
+	return &GitHubReporter{w: w, env: env}
+}
+
+// SuiteFinished writes the annotations and job summary for suite.
+func (r *GitHubReporter) SuiteFinished(suite run.SuiteResult) {
+	r.err = WriteGitHub(r.w, r.env, suite)
+}
+
+// Err returns the error from writing, if any.
+func (r *GitHubReporter) Err() error {
+	return r.err
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+func TestWriteGitHubAnnotatesFailedTests(t *testing.T) {
+	workspace := t.TempDir()
+	root := filepath.Join(workspace, "e2e")
+	mustWrite(t, filepath.Join(root, "checkout.test.md"), "---\ncases: sections\n---\n# Checkout\n\n## Add to cart\n\n1. Add a mug.\n\n## Apply coupon\n\n1. Apply SAVE10.\n")
+	mustWrite(t, filepath.Join(root, "login.test.md"), "# Login\n\n1. Open the login page.\n2. Sign in as admin.\n")
+	summaryPath := filepath.Join(workspace, "summary.md")
+	mustWrite(t, summaryPath, "earlier step\n")
+
+	suite := run.SuiteResult{
+		Total:   3,
+		Passed:  1,
+		Failed:  2,
+		RootAbs: root,
+		Results: []run.TestResult{
+			{
+				ID: "checkout.test.md", TestRel: "checkout.test.md", Status: run.TestFail,
+				Reason: "1 of 2 cases failed (agent exit code 0)",
+				Cases: []run.CaseResult{
+					{Name: "Add to cart", Status: run.TestPass},
+					{Name: "Apply coupon", Status: run.TestFail, Reason: "got 15%, want 10%"},
+				},
+			},
+			{
+				ID: "login.test.md", TestRel: "login.test.md", Status: run.TestFail,
+				Reason: "status=fail: 500 error (agent exit code 0)",
+				Steps:  []logs.Step{{Name: "sign in as admin", Status: logs.StatusFail, Reason: "server error\nretry failed"}},
+			},
+			{ID: "ok.test.md", TestRel: "ok.test.md", Status: run.TestPass},
+		},
+		Hooks: []run.HookResult{{HookRel: "_setup.md", Kind: run.HookSetup, Status: run.TestFail, Reason: "no log"}},
+	}
+
+	var out bytes.Buffer
+	if err := WriteGitHub(&out, GitHubEnv{Workspace: workspace, StepSummary: summaryPath}, suite); err != nil {
+		t.Fatalf("WriteGitHub returned error: %v", err)
+	}
+	want := "::error file=e2e/checkout.test.md,line=10,title=checkout.test.md%3A case \"Apply coupon\" failed::got 15%25, want 10%25\n" +
+		"::error file=e2e/login.test.md,line=4,title=login.test.md%3A step \"sign in as admin\" failed::server error%0Aretry failed\n" +
+		"::error file=e2e/_setup.md,title=setup _setup.md failed::no log\n"
+	if out.String() != want {
+		t.Fatalf("annotations =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	summary := mustRead(t, summaryPath)
+	if !strings.HasPrefix(summary, "earlier step\n# mdtest: e2e\n") {
+		t.Fatalf("summary = %q, want appended report", summary)
+	}
+	if strings.Contains(summary, "---\n") || strings.Contains(summary, "[log]") {
+		t.Fatalf("summary = %q, want no front matter or log links", summary)
+	}
+}
+
+func TestLookupGitHubEnv(t *testing.T) {
+	env := map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_STEP_SUMMARY": "/tmp/summary"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	got, ok := LookupGitHubEnv(lookup)
+	if !ok || got.StepSummary != "/tmp/summary" {
+		t.Fatalf("LookupGitHubEnv = %#v, %v", got, ok)
+	}
+	env["GITHUB_ACTIONS"] = "false"
+	if _, ok := LookupGitHubEnv(lookup); ok {
+		t.Fatal("LookupGitHubEnv ok outside GitHub Actions")
+	}
+}
@@ -0,0 +1,358 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+// WriteHTML writes suite as a static site under dir: index.html with a
+// filterable summary table and run metadata, and one page per test with its
+// rendered log, agent transcript and fixture output. Artifacts inside each
+// log directory are copied into the site so dir can be uploaded on its own.
+func WriteHTML(dir string, suite run.SuiteResult) error {
+	for _, sub := range []string{"", "tests", "files"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return err
+		}
+	}
+
+	index := htmlIndex{
+		Suite:    suiteName(suite),
+		RanAt:    suite.StartedAt.UTC().Format(time.RFC3339),
+		Duration: formatDuration(suite.Elapsed),
+		Counts:   countsSentence(suite),
+		Total:    suite.Total,
+		Settings: runSettings(suite),
+	}
+	seen := map[run.TestStatus]bool{}
+	for i, result := range suite.Results {
+		slug := fmt.Sprintf("%03d-%s", i+1, slugPattern.ReplaceAllString(result.ID, "-"))
+		page, err := buildTestPage(dir, slug, index.Suite, result)
+		if err != nil {
+			return fmt.Errorf("%s: %w", result.ID, err)
+		}
+		if err := writeFile(filepath.Join(dir, "tests", slug+".html"), func(w io.Writer) error {
+			return testPageTemplate.Execute(w, page)
+		}); err != nil {
+			return err
+		}
+		if !seen[result.Status] {
+			seen[result.Status] = true
+			index.Statuses = append(index.Statuses, string(result.Status))
+		}
+		index.Rows = append(index.Rows, htmlRow{
+			ID:       result.ID,
+			Status:   string(result.Status),
+			Label:    statusLabels[result.Status],
+			Reason:   result.Reason,
+			Duration: formatDuration(result.Elapsed),
+			Page:     "tests/" + slug + ".html",
+		})
+	}
+	for _, hook := range suite.Hooks {
+		if hook.Status != run.TestPass {
+			index.Hooks = append(index.Hooks, hook)
+		}
+	}
+	return writeFile(filepath.Join(dir, "index.html"), func(w io.Writer) error {
+		return indexTemplate.Execute(w, index)
+	})
+}
+
+var (
+	slugPattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	// ansiPattern matches the terminal escapes a pty transcript carries.
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07]*\x07|\r`)
+)
+
+type htmlIndex struct {
+	Suite    string
+	RanAt    string
+	Duration string
+	Counts   string
+	Total    int
+	Settings [][2]string
+	Statuses []string
+	Rows     []htmlRow
+	Hooks    []run.HookResult
+}
+
+type htmlRow struct {
+	ID, Status, Label, Reason, Duration, Page string
+}
+
+type htmlTestPage struct {
+	Suite       string
+	Result      run.TestResult
+	Label       string
+	Duration    string
+	Cases       []run.CaseResult
+	LogName     string
+	FrontMatter string
+	LogHTML     template.HTML
+	Artifacts   []htmlLink
+	Transcript  string
+	Fixtures    string
+}
+
+type htmlLink struct {
+	Name, Href string
+}
+
+func buildTestPage(dir, slug, suiteName string, result run.TestResult) (htmlTestPage, error) {
+	page := htmlTestPage{
+		Suite:    suiteName,
+		Result:   result,
+		Label:    statusLabels[result.Status],
+		Duration: formatDuration(result.Elapsed),
+	}
+	for _, c := range result.Cases {
+		c.Status, c.Reason = caseStatus(result, c)
+		page.Cases = append(page.Cases, c)
+	}
+	files := &artifactCopier{siteDir: dir, destDir: filepath.Join(dir, "files", slug)}
+	if result.LogAbs != "" {
+		page.LogName = filepath.Base(result.LogAbs)
+		files.logDir = filepath.Dir(result.LogAbs)
+		if content, err := os.ReadFile(result.LogAbs); err == nil {
+			frontMatter, body := cutFrontMatter(string(content))
+			page.FrontMatter = frontMatter
+			page.LogHTML = template.HTML(renderMarkdown(body, files.link))
+		}
+	}
+	for _, artifact := range result.Artifacts {
+		page.Artifacts = append(page.Artifacts, htmlLink{Name: artifact, Href: files.link(artifact)})
+	}
+	if files.err != nil {
+		return htmlTestPage{}, files.err
+	}
+	page.Transcript = readOptional(result.TranscriptAbs)
+	page.Fixtures = readOptional(result.FixturesAbs)
+	return page, nil
+}
+
+// artifactCopier copies files a log refers to into the site and returns
+// links to the copies, relative to a test page. Files outside the log
+// directory are never copied.
+type artifactCopier struct {
+	siteDir string
+	destDir string
+	logDir  string
+	err     error
+}
+
+func (c *artifactCopier) link(target string) string {
+	if c.logDir == "" || target == "" || strings.HasPrefix(target, "#") || strings.Contains(target, ":") {
+		return safeHref(target)
+	}
+	decoded, err := url.PathUnescape(target)
+	if err != nil {
+		decoded = target
+	}
+	src := filepath.Join(c.logDir, filepath.FromSlash(decoded))
+	rel, err := filepath.Rel(c.logDir, src)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "#"
+	}
+	info, err := os.Stat(src)
+	if err != nil || info.IsDir() {
+		return "#"
+	}
+	dst := filepath.Join(c.destDir, rel)
+	if _, err := os.Stat(dst); err != nil {
+		if err := copyFile(src, dst); err != nil && c.err == nil {
+			c.err = err
+		}
+	}
+	return "../" + relativeLink(c.siteDir, dst)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFile(dst, func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
+
+// cutFrontMatter splits a log into its front matter and Markdown body. A
+// log without front matter is all body.
+func cutFrontMatter(content string) (string, string) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content
+	}
+	rest := content[len("---\n"):]
+	if strings.HasPrefix(rest, "---\n") {
+		return "", rest[len("---\n"):]
+	}
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if strings.HasSuffix(rest, "\n---") {
+			return rest[:len(rest)-len("\n---")], ""
+		}
+		return "", content
+	}
+	return rest[:end], rest[end+len("\n---\n"):]
+}
+
+func readOptional(path string) string {
+	if path == "" {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return ansiPattern.ReplaceAllString(string(content), "")
+}
+
+const htmlStyle = `<style>
+body{font-family:system-ui,sans-serif;margin:2rem auto;max-width:64rem;padding:0 1rem;color:#222}
+table{border-collapse:collapse;width:100%;margin:1rem 0}
+th,td{border:1px solid #ddd;padding:.4rem .6rem;text-align:left;vertical-align:top}
+th{background:#f5f5f5}
+pre{background:#f6f8fa;padding:.8rem;overflow:auto;white-space:pre-wrap}
+code{background:#f6f8fa;padding:0 .2rem}
+pre code{padding:0}
+img{max-width:100%}
+.status-pass{color:#1a7f37}.status-fail,.status-error,.status-xpass{color:#cf222e}
+.status-skip,.status-xfail{color:#9a6700}
+.filters button{margin-right:.4rem}
+.filters button.active{font-weight:bold}
+.log{border-left:3px solid #ddd;padding-left:1rem}
+</style>`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>mdtest: {{.Suite}}</title>
+` + htmlStyle + `
+</head>
+<body>
+<h1>mdtest: {{.Suite}}</h1>
+<p><strong>{{.Counts}}</strong> of {{.Total}} tests in {{.Duration}}, ran at {{.RanAt}}.</p>
+<div class="filters">
+<button type="button" class="active" data-filter="">all</button>
+{{- range .Statuses}}
+<button type="button" data-filter="{{.}}">{{.}}</button>
+{{- end}}
+</div>
+<table id="results">
+<thead><tr><th>Status</th><th>Test</th><th>Duration</th><th>Reason</th></tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr data-status="{{.Status}}"><td class="status-{{.Status}}">{{.Label}}</td><td><a href="{{.Page}}"><code>{{.ID}}</code></a></td><td>{{.Duration}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- if .Hooks}}
+<h2>Failed hooks</h2>
+<ul>
+{{- range .Hooks}}
+<li>{{.Kind}} <code>{{.HookRel}}</code>: {{.Reason}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<h2>Run</h2>
+<table>
+<thead><tr><th>Setting</th><th>Value</th></tr></thead>
+<tbody>
+{{- range .Settings}}
+<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+document.querySelectorAll(".filters button").forEach(function (button) {
+  button.addEventListener("click", function () {
+    var filter = button.getAttribute("data-filter");
+    document.querySelectorAll(".filters button").forEach(function (b) { b.classList.toggle("active", b === button); });
+    document.querySelectorAll("#results tbody tr").forEach(function (row) {
+      row.style.display = !filter || row.getAttribute("data-status") === filter ? "" : "none";
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+var testPageTemplate = template.Must(template.New("test").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Result.ID}} - mdtest: {{.Suite}}</title>
+` + htmlStyle + `
+</head>
+<body>
+<p><a href="../index.html">&larr; {{.Suite}}</a></p>
+<h1><code>{{.Result.ID}}</code></h1>
+<p class="status-{{.Result.Status}}"><strong>{{.Label}}</strong> in {{.Duration}}</p>
+{{- if .Result.Reason}}
+<p>{{.Result.Reason}}</p>
+{{- end}}
+{{- if .Cases}}
+<h2>Cases</h2>
+<table>
+<thead><tr><th>Status</th><th>Case</th><th>Reason</th></tr></thead>
+<tbody>
+{{- range .Cases}}
+<tr><td class="status-{{.Status}}">{{.Status}}</td><td>{{.Name}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- if .Result.Steps}}
+<h2>Steps</h2>
+<table>
+<thead><tr><th>Status</th><th>Step</th><th>Reason</th></tr></thead>
+<tbody>
+{{- range .Result.Steps}}
+<tr><td class="status-{{.Status}}">{{.Status}}</td><td>{{.Name}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- if .Artifacts}}
+<h2>Artifacts</h2>
+<ul>
+{{- range .Artifacts}}
+<li><a href="{{.Href}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .LogName}}
+<h2>Log <code>{{.LogName}}</code></h2>
+{{- if .FrontMatter}}
+<pre>{{.FrontMatter}}</pre>
+{{- end}}
+<div class="log">
+{{.LogHTML}}
+</div>
+{{- end}}
+{{- if .Transcript}}
+<h2>Agent transcript</h2>
+<pre>{{.Transcript}}</pre>
+{{- end}}
+{{- if .Fixtures}}
+<h2>Fixture output</h2>
+<pre>{{.Fixtures}}</pre>
+{{- end}}
+</body>
+</html>
+`))
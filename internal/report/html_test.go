@@ -0,0 +1,173 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+func TestWriteHTMLBuildsBrowsableSite(t *testing.T) {
+	root := t.TempDir()
+	logDir := filepath.Join(root, "checkout.logs")
+	logAbs := filepath.Join(logDir, "20260210.log.md")
+	mustWrite(t, logAbs, "---\nstatus: fail\nartifacts: [shots/coupon.png]\n---\n# Checkout\n\nApplied **coupon**, see ![coupon](shots/coupon.png).\n\n<script>alert(1)</script>\n")
+	mustWrite(t, filepath.Join(logDir, "shots", "coupon.png"), "png")
+	mustWrite(t, filepath.Join(root, "secret.txt"), "secret")
+	transcriptAbs := filepath.Join(logDir, "20260210.transcript.txt")
+	mustWrite(t, transcriptAbs, "\x1b[1mthinking\x1b[0m <done>\n")
+
+	suite := run.SuiteResult{
+		Total:     2,
+		Passed:    1,
+		Failed:    1,
+		StartedAt: time.Date(2026, 2, 10, 14, 30, 0, 0, time.UTC),
+		RootAbs:   root,
+		Config:    run.Config{Agent: agent.CodexAgent},
+		Results: []run.TestResult{
+			{ID: "login.test.md", Status: run.TestPass},
+			{
+				ID: "checkout.test.md", Status: run.TestFail, Reason: "status=fail: <wrong> discount",
+				LogAbs: logAbs, TranscriptAbs: transcriptAbs,
+				Artifacts: []string{"shots/coupon.png", "../secret.txt"},
+			},
+		},
+	}
+	site := filepath.Join(root, "site")
+	if err := WriteHTML(site, suite); err != nil {
+		t.Fatalf("WriteHTML returned error: %v", err)
+	}
+
+	index := mustRead(t, filepath.Join(site, "index.html"))
+	for _, want := range []string{
+		`<tr data-status="fail">`,
+		`href="tests/002-checkout.test.md.html"`,
+		`<button type="button" data-filter="pass">pass</button>`,
+		`status=fail: &lt;wrong&gt; discount`,
+		`<td>Agent</td><td>codex</td>`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Fatalf("index.html missing %q:\n%s", want, index)
+		}
+	}
+
+	page := mustRead(t, filepath.Join(site, "tests", "002-checkout.test.md.html"))
+	for _, want := range []string{
+		"<h1>Checkout</h1>",
+		"<strong>coupon</strong>",
+		`<img src="../files/002-checkout.test.md/shots/coupon.png" alt="coupon">`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		"<pre>status: fail\nartifacts: [shots/coupon.png]</pre>",
+		"<pre>thinking &lt;done&gt;\n</pre>",
+		`<a href="#">../secret.txt</a>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("test page missing %q:\n%s", want, page)
+		}
+	}
+	if got := mustRead(t, filepath.Join(site, "files", "002-checkout.test.md", "shots", "coupon.png")); got != "png" {
+		t.Fatalf("copied artifact = %q, want png", got)
+	}
+	if _, err := os.Stat(filepath.Join(site, "files", "secret.txt")); err == nil {
+		t.Fatal("file outside the log directory was copied")
+	}
+}
+
+func TestWriteHTMLAppliesXFailToCases(t *testing.T) {
+	root := t.TempDir()
+	suite := run.SuiteResult{
+		Total:   1,
+		XFailed: 1,
+		RootAbs: root,
+		Results: []run.TestResult{
+			{
+				ID: "checkout.test.md", Status: run.TestXFail, Reason: "xfail: coupon bug #12",
+				Cases: []run.CaseResult{
+					{Name: "add to cart", Status: run.TestPass},
+					{Name: "apply coupon", Status: run.TestFail, Reason: "got 15%"},
+				},
+			},
+		},
+	}
+	site := filepath.Join(root, "site")
+	if err := WriteHTML(site, suite); err != nil {
+		t.Fatalf("WriteHTML returned error: %v", err)
+	}
+
+	page := mustRead(t, filepath.Join(site, "tests", "001-checkout.test.md.html"))
+	for _, want := range []string{
+		`<tr><td class="status-pass">pass</td><td>add to cart</td><td></td></tr>`,
+		`<tr><td class="status-xfail">xfail</td><td>apply coupon</td><td>got 15%</td></tr>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("test page missing %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestRenderMarkdownBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "heading", src: "## Steps", want: "<h2>Steps</h2>\n"},
+		{name: "list", src: "- one\n- `two`", want: "<ul>\n<li>one</li>\n<li><code>two</code></li>\n</ul>\n"},
+		{name: "ordered", src: "1. a\n2. b", want: "<ol>\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{name: "fence", src: "```go\nx := <-ch\n```", want: "<pre><code class=\"language-go\">x := &lt;-ch</code></pre>\n"},
+		{name: "table", src: "| a | b |\n| --- | --- |\n| 1 | 2 |", want: "<table>\n<thead><tr><th>a</th><th>b</th></tr></thead>\n<tbody>\n<tr><td>1</td><td>2</td></tr>\n</tbody>\n</table>\n"},
+		{name: "link", src: "[docs](https://example.com) and *em*", want: "<p><a href=\"https://example.com\">docs</a> and <em>em</em></p>\n"},
+		{name: "script link", src: "[x](javascript:alert(1))", want: "<p><a href=\"#\">x</a>)</p>\n"},
+		{name: "data link", src: "[x](data:text/html,hi)", want: "<p><a href=\"#\">x</a></p>\n"},
+		{name: "vbscript link", src: "[x](VBScript:msgbox)", want: "<p><a href=\"#\">x</a></p>\n"},
+		{name: "leading control character", src: "[x](\x01javascript:alert)", want: "<p><a href=\"#\">x</a></p>\n"},
+		{name: "mailto link", src: "[x](MAILTO:qa@example.com)", want: "<p><a href=\"MAILTO:qa@example.com\">x</a></p>\n"},
+		{name: "relative link", src: "[x](shots/a:b.png)", want: "<p><a href=\"shots/a:b.png\">x</a></p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderMarkdown(tt.src, nil); got != tt.want {
+				t.Fatalf("renderMarkdown(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArtifactLinksAllowOnlyWebAndRelativeTargets(t *testing.T) {
+	c := &artifactCopier{logDir: t.TempDir(), siteDir: t.TempDir()}
+	for target, want := range map[string]string{
+		"https://example.com/a.png": "https://example.com/a.png",
+		"mailto:qa@example.com":     "mailto:qa@example.com",
+		"data:text/html,hi":         "#",
+		"vbscript:msgbox":           "#",
+		" javascript:alert(1)":      "#",
+		"\x01JavaScript:alert(1)":   "#",
+	} {
+		if got := c.link(target); got != want {
+			t.Fatalf("link(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func mustWrite(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(content)
+}
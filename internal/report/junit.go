@@ -0,0 +1,145 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes suite as JUnit XML. Each test is a <testcase>; a
+// multi-case file contributes one <testcase> per named case instead, plus
+// an erroring <testcase> for the file when it ended in an error after its
+// cases were read, such as a failed after: fixture.
+// Expected failures are skipped, unexpected passes fail, and failed hooks are
+// reported as errors.
+func WriteJUnit(w io.Writer, suite run.SuiteResult) error {
+	js := junitSuite{Name: "mdtest", Time: seconds(suite.Elapsed)}
+	for _, result := range suite.Results {
+		if len(result.Cases) == 0 {
+			js.add(junitCase{
+				ClassName: result.TestRel,
+				Name:      result.ID,
+				Time:      seconds(result.Elapsed),
+				SystemOut: logOutput(result.LogAbs),
+			}, result.Status, result.Reason)
+			continue
+		}
+		// The agent runs all cases at once; its time goes to the first.
+		elapsed := result.Elapsed
+		for _, c := range result.Cases {
+			status, reason := caseStatus(result, c)
+			js.add(junitCase{
+				ClassName: result.ID,
+				Name:      c.Name,
+				Time:      seconds(elapsed),
+				SystemOut: logOutput(result.LogAbs),
+			}, status, reason)
+			elapsed = 0
+		}
+		if result.Status == run.TestError {
+			js.add(junitCase{
+				ClassName: result.TestRel,
+				Name:      result.ID,
+				Time:      seconds(0),
+				SystemOut: logOutput(result.LogAbs),
+			}, run.TestError, result.Reason)
+		}
+	}
+	for _, hook := range suite.Hooks {
+		if hook.Status == run.TestPass {
+			continue
+		}
+		js.add(junitCase{
+			ClassName: hook.HookRel,
+			Name:      string(hook.Kind),
+			Time:      seconds(0),
+			SystemOut: logOutput(hook.LogAbs),
+		}, run.TestError, hook.Reason)
+	}
+
+	doc := junitSuites{
+		Tests:    js.Tests,
+		Failures: js.Failures,
+		Errors:   js.Errors,
+		Skipped:  js.Skipped,
+		Time:     js.Time,
+		Suites:   []junitSuite{js},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (s *junitSuite) add(c junitCase, status run.TestStatus, reason string) {
+	s.Tests++
+	message := &junitMessage{Message: reason, Text: reason}
+	switch status {
+	case run.TestPass:
+	case run.TestSkip, run.TestXFail:
+		s.Skipped++
+		c.Skipped = &junitMessage{Message: reason}
+	case run.TestError:
+		s.Errors++
+		c.Error = message
+	default:
+		s.Failures++
+		c.Failure = message
+	}
+	s.Cases = append(s.Cases, c)
+}
+
+func logOutput(logAbs string) string {
+	if logAbs == "" {
+		return ""
+	}
+	return "log: " + logAbs
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
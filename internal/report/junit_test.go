@@ -0,0 +1,157 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+func TestWriteJUnitExpandsCasesAndCountsStatuses(t *testing.T) {
+	suite := run.SuiteResult{
+		Elapsed: 3 * time.Second,
+		Results: []run.TestResult{
+			{ID: "login.test.md", TestRel: "login.test.md", LogAbs: "/s/login.logs/x.log.md", Status: run.TestPass, Elapsed: time.Second},
+			{
+				ID: "checkout.test.md", TestRel: "checkout.test.md", Status: run.TestFail, Reason: "1 of 2 cases failed",
+				Cases: []run.CaseResult{
+					{Name: "add to cart", Status: run.TestPass},
+					{Name: "apply coupon", Status: run.TestFail, Reason: "got 15%"},
+				},
+			},
+			{ID: "pay.test.md", TestRel: "pay.test.md", Status: run.TestSkip, Reason: "dependency failed"},
+			{ID: "db.test.md", TestRel: "db.test.md", Status: run.TestError, Reason: "before fixture failed"},
+		},
+		Hooks: []run.HookResult{
+			{HookRel: "_setup.md", Kind: run.HookSetup, Status: run.TestPass},
+			{HookRel: "api/_teardown.md", Kind: run.HookTeardown, Status: run.TestFail, Reason: "status=fail"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, suite); err != nil {
+		t.Fatalf("WriteJUnit returned error: %v", err)
+	}
+
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 6 || doc.Failures != 1 || doc.Errors != 2 || doc.Skipped != 1 || doc.Time != "3.000" {
+		t.Fatalf("totals = %+v", doc)
+	}
+	cases := doc.Suites[0].Cases
+	if cases[0].Name != "login.test.md" || cases[0].SystemOut != "log: /s/login.logs/x.log.md" || cases[0].Time != "1.000" {
+		t.Fatalf("first testcase = %+v", cases[0])
+	}
+	if cases[2].ClassName != "checkout.test.md" || cases[2].Name != "apply coupon" || cases[2].Failure == nil || cases[2].Failure.Message != "got 15%" {
+		t.Fatalf("case testcase = %+v", cases[2])
+	}
+	if cases[5].ClassName != "api/_teardown.md" || cases[5].Error == nil {
+		t.Fatalf("hook testcase = %+v", cases[5])
+	}
+}
+
+func TestWriteJUnitAppliesXFailToCases(t *testing.T) {
+	suite := run.SuiteResult{
+		Results: []run.TestResult{
+			{
+				ID: "checkout.test.md", TestRel: "checkout.test.md", Status: run.TestXFail, Reason: "xfail: coupon bug #12",
+				Cases: []run.CaseResult{
+					{Name: "add to cart", Status: run.TestPass},
+					{Name: "apply coupon", Status: run.TestFail, Reason: "got 15%"},
+				},
+			},
+			{
+				ID: "refund.test.md", TestRel: "refund.test.md", Status: run.TestXPass, Reason: "xpass: marked xfail but passed",
+				Cases: []run.CaseResult{
+					{Name: "full refund", Status: run.TestPass},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, suite); err != nil {
+		t.Fatalf("WriteJUnit returned error: %v", err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Errors != 0 || doc.Skipped != 1 {
+		t.Fatalf("totals = %+v, want the xfail case skipped and the xpass case failed", doc)
+	}
+	cases := doc.Suites[0].Cases
+	if cases[1].Name != "apply coupon" || cases[1].Skipped == nil || cases[1].Failure != nil {
+		t.Fatalf("xfail case = %+v, want skipped", cases[1])
+	}
+	if cases[2].Name != "full refund" || cases[2].Failure == nil || cases[2].Failure.Message != "xpass: marked xfail but passed" {
+		t.Fatalf("xpass case = %+v, want failure", cases[2])
+	}
+}
+
+func TestWriteJUnitReportsErrorOfMultiCaseFile(t *testing.T) {
+	suite := run.SuiteResult{
+		Results: []run.TestResult{
+			{
+				ID: "checkout.test.md", TestRel: "checkout.test.md", Status: run.TestError,
+				Reason: "after: fixture exited 1; test 1 of 2 cases failed",
+				Cases: []run.CaseResult{
+					{Name: "add to cart", Status: run.TestPass},
+					{Name: "apply coupon", Status: run.TestFail, Reason: "got 15%"},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, suite); err != nil {
+		t.Fatalf("WriteJUnit returned error: %v", err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Errors != 1 {
+		t.Fatalf("totals = %+v, want both cases and the file's error", doc)
+	}
+	errCase := doc.Suites[0].Cases[2]
+	if errCase.Name != "checkout.test.md" || errCase.Error == nil || errCase.Error.Message != suite.Results[0].Reason {
+		t.Fatalf("error case = %+v, want the after: fixture failure", errCase)
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Spec
+		wantErr bool
+	}{
+		{raw: "junit=out/report.xml", want: Spec{Format: FormatJUnit, Path: "out/report.xml"}},
+		{raw: "JUnit=a=b.xml", want: Spec{Format: FormatJUnit, Path: "a=b.xml"}},
+		{raw: "junit", wantErr: true},
+		{raw: "junit=", wantErr: true},
+		{raw: "pdf=out.pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseSpec(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ParseSpec returned nil error, want failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSpec returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseSpec = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
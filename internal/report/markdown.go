@@ -0,0 +1,247 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+type markdownFrontMatter struct {
+	Suite    string          `yaml:"suite"`
+	RanAt    string          `yaml:"ran_at"`
+	Duration string          `yaml:"duration"`
+	Total    int             `yaml:"total"`
+	Passed   int             `yaml:"passed"`
+	Failed   int             `yaml:"failed"`
+	Skipped  int             `yaml:"skipped,omitempty"`
+	Errors   int             `yaml:"errors,omitempty"`
+	XFailed  int             `yaml:"xfailed,omitempty"`
+	XPassed  int             `yaml:"xpassed,omitempty"`
+	Cases    []markdownEntry `yaml:"cases"`
+}
+
+type markdownEntry struct {
+	Name   string          `yaml:"name"`
+	Status string          `yaml:"status"`
+	Reason string          `yaml:"reason,omitempty"`
+	Log    string          `yaml:"log,omitempty"`
+	Cases  []markdownEntry `yaml:"cases,omitempty"`
+}
+
+var statusLabels = map[run.TestStatus]string{
+	run.TestPass:  "✅ pass",
+	run.TestFail:  "❌ fail",
+	run.TestSkip:  "⏭️ skip",
+	run.TestError: "⚠️ error",
+	run.TestXFail: "🐛 xfail",
+	run.TestXPass: "❗ xpass",
+}
+
+// WriteMarkdown writes suite as a Markdown report in the shape of a test
+// log: YAML front matter summarizing the run, then tables meant to be
+// pasted into a PR comment. Log links are relative to reportDir.
+func WriteMarkdown(w io.Writer, suite run.SuiteResult, reportDir string) error {
+	fm := markdownFrontMatter{
+		Suite:    suiteName(suite),
+		RanAt:    suite.StartedAt.UTC().Format(time.RFC3339),
+		Duration: formatDuration(suite.Elapsed),
+		Total:    suite.Total,
+		Passed:   suite.Passed,
+		Failed:   suite.Failed,
+		Skipped:  suite.Skipped,
+		Errors:   suite.Errored,
+		XFailed:  suite.XFailed,
+		XPassed:  suite.XPassed,
+		Cases:    make([]markdownEntry, 0, len(suite.Results)),
+	}
+	for _, result := range suite.Results {
+		entry := markdownEntry{
+			Name:   result.ID,
+			Status: string(result.Status),
+			Reason: result.Reason,
+			Log:    relativeLink(reportDir, result.LogAbs),
+		}
+		for _, c := range result.Cases {
+			status, reason := caseStatus(result, c)
+			entry.Cases = append(entry.Cases, markdownEntry{Name: c.Name, Status: string(status), Reason: reason})
+		}
+		fm.Cases = append(fm.Cases, entry)
+	}
+	header, err := yaml.Marshal(fm)
+	if err != nil {
+		return fmt.Errorf("marshal front matter: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.Write(header)
+	b.WriteString("---\n\n")
+	writeMarkdownBody(&b, suite, reportDir)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownBody writes the tables of a Markdown report. An empty
+// reportDir leaves out the log links.
+func writeMarkdownBody(b *strings.Builder, suite run.SuiteResult, reportDir string) {
+	fmt.Fprintf(b, "# mdtest: %s\n\n", suiteName(suite))
+	fmt.Fprintf(b, "**%s** of %d tests in %s.\n\n", countsSentence(suite), suite.Total, formatDuration(suite.Elapsed))
+
+	if reportDir != "" {
+		b.WriteString("| Status | Test | Duration | Log |\n| --- | --- | --- | --- |\n")
+	} else {
+		b.WriteString("| Status | Test | Duration |\n| --- | --- | --- |\n")
+	}
+	for _, result := range suite.Results {
+		fmt.Fprintf(b, "| %s | `%s` | %s |", statusLabels[result.Status], result.ID, formatDuration(result.Elapsed))
+		if reportDir != "" {
+			log := ""
+			if link := relativeLink(reportDir, result.LogAbs); link != "" {
+				log = fmt.Sprintf("[log](%s)", link)
+			}
+			fmt.Fprintf(b, " %s |", log)
+		}
+		b.WriteString("\n")
+	}
+
+	problems := 0
+	for _, result := range suite.Results {
+		if result.Status == run.TestPass {
+			continue
+		}
+		if problems == 0 {
+			b.WriteString("\n## Problems\n")
+		}
+		problems++
+		fmt.Fprintf(b, "\n### %s `%s`\n\n%s\n", statusLabels[result.Status], result.ID, tableSafe(result.Reason))
+		for _, c := range result.Cases {
+			status, reason := caseStatus(result, c)
+			if status == run.TestPass {
+				continue
+			}
+			fmt.Fprintf(b, "\n- %s %s: %s", statusLabels[status], c.Name, tableSafe(reason))
+		}
+		if len(result.Cases) > 0 {
+			b.WriteString("\n")
+		}
+	}
+	for _, hook := range suite.Hooks {
+		if hook.Status == run.TestPass {
+			continue
+		}
+		if problems == 0 {
+			b.WriteString("\n## Problems\n")
+		}
+		problems++
+		fmt.Fprintf(b, "\n### %s %s `%s`\n\n%s\n", statusLabels[run.TestError], hook.Kind, hook.HookRel, tableSafe(hook.Reason))
+	}
+
+	b.WriteString("\n## Run\n\n| Setting | Value |\n| --- | --- |\n")
+	for _, row := range runSettings(suite) {
+		fmt.Fprintf(b, "| %s | %s |\n", row[0], tableSafe(row[1]))
+	}
+}
+
+func suiteName(suite run.SuiteResult) string {
+	if suite.RootAbs == "" {
+		return "mdtest"
+	}
+	return filepath.Base(suite.RootAbs)
+}
+
+func countsSentence(suite run.SuiteResult) string {
+	parts := []string{fmt.Sprintf("%d passed", suite.Passed), fmt.Sprintf("%d failed", suite.Failed)}
+	for _, extra := range []struct {
+		n     int
+		label string
+	}{
+		{suite.Skipped, "skipped"},
+		{suite.Errored, "errors"},
+		{suite.XFailed, "expected failures"},
+		{suite.XPassed, "unexpected passes"},
+	} {
+		if extra.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", extra.n, extra.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func runSettings(suite run.SuiteResult) [][2]string {
+	cfg := suite.Config
+	promptMode := string(cfg.PromptMode)
+	if promptMode == "" {
+		promptMode = string(run.PromptModePath)
+	}
+	order := string(suite.Order)
+	if suite.Order == run.OrderRandom {
+		order += fmt.Sprintf(" (seed %d)", suite.Seed)
+	}
+	rows := [][2]string{
+		{"Root", suite.RootAbs},
+		{"Agent", string(cfg.Agent)},
+		{"Order", order},
+		{"Prompt mode", promptMode},
+	}
+	if len(cfg.Files) > 0 {
+		rows = append(rows, [2]string{"Selected", strings.Join(cfg.Files, ", ")})
+	}
+	if len(cfg.Vars) > 0 {
+		// Values can be credentials, so only names are reported.
+		names := make([]string, 0, len(cfg.Vars))
+		for name := range cfg.Vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		rows = append(rows, [2]string{"--var", strings.Join(names, ", ")})
+	}
+	for _, flag := range []struct {
+		on   bool
+		name string
+	}{
+		{cfg.Interactive, "--interactive"},
+		{cfg.DangerouslyAllowAllActions, "--dangerously-allow-all-actions"},
+		{cfg.StrictLog, "--strict-log"},
+		{cfg.RunXFail, "--run-xfail"},
+	} {
+		if flag.on {
+			rows = append(rows, [2]string{flag.name, "yes"})
+		}
+	}
+	if cfg.RepairLimit > 0 {
+		rows = append(rows, [2]string{"--log-repairs", fmt.Sprint(cfg.RepairLimit)})
+	}
+	return rows
+}
+
+// relativeLink returns targetAbs relative to dir as a Markdown link target.
+func relativeLink(dir string, targetAbs string) string {
+	if targetAbs == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(dir, targetAbs)
+	if err != nil {
+		return filepath.ToSlash(targetAbs)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), " ", "%20")
+}
+
+// tableSafe keeps text on one line and inside one Markdown table cell.
+func tableSafe(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+func formatDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+func TestWriteMarkdownSummarizesRun(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "work", "shop")
+	suite := run.SuiteResult{
+		Total:     3,
+		Passed:    1,
+		Failed:    1,
+		Skipped:   1,
+		Order:     run.OrderRandom,
+		Seed:      7,
+		StartedAt: time.Date(2026, 2, 10, 14, 30, 0, 0, time.UTC),
+		Elapsed:   2*time.Minute + 3*time.Second,
+		RootAbs:   root,
+		Config:    run.Config{Agent: agent.ClaudeAgent, Vars: map[string]string{"PASSWORD": "hunter2"}},
+		Results: []run.TestResult{
+			{ID: "login.test.md", Status: run.TestPass, LogAbs: filepath.Join(root, "login.logs", "x.log.md"), Elapsed: 12 * time.Second},
+			{
+				ID: "checkout.test.md", Status: run.TestFail, Reason: "1 of 2 cases | failed",
+				LogAbs: filepath.Join(root, "checkout.logs", "x.log.md"),
+				Cases: []run.CaseResult{
+					{Name: "add to cart", Status: run.TestPass},
+					{Name: "apply coupon", Status: run.TestFail, Reason: "got 15%"},
+				},
+			},
+			{ID: "pay.test.md", Status: run.TestSkip, Reason: "skip: needs staging"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, suite, filepath.Join(root, "reports")); err != nil {
+		t.Fatalf("WriteMarkdown returned error: %v", err)
+	}
+	got := buf.String()
+
+	front, body, ok := strings.Cut(strings.TrimPrefix(got, "---\n"), "\n---\n")
+	if !ok {
+		t.Fatalf("report has no front matter:\n%s", got)
+	}
+	var fm markdownFrontMatter
+	if err := yaml.Unmarshal([]byte(front), &fm); err != nil {
+		t.Fatalf("front matter is not YAML: %v", err)
+	}
+	if fm.Suite != "shop" || fm.RanAt != "2026-02-10T14:30:00Z" || fm.Passed != 1 || fm.Failed != 1 || len(fm.Cases) != 3 {
+		t.Fatalf("front matter = %+v", fm)
+	}
+	if fm.Cases[1].Cases[1].Reason != "got 15%" || fm.Cases[0].Log != "../login.logs/x.log.md" {
+		t.Fatalf("front matter cases = %+v", fm.Cases)
+	}
+
+	for _, want := range []string{
+		"**1 passed, 1 failed, 1 skipped** of 3 tests in 2m3s.",
+		"| ✅ pass | `login.test.md` | 12s | [log](../login.logs/x.log.md) |",
+		"### ❌ fail `checkout.test.md`\n\n1 of 2 cases \\| failed\n",
+		"- ❌ fail apply coupon: got 15%",
+		"| Order | random (seed 7) |",
+		"| --var | PASSWORD |",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("report missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "hunter2") {
+		t.Fatalf("report leaks a --var value:\n%s", got)
+	}
+}
+
+func TestWriteMarkdownAppliesXFailToCases(t *testing.T) {
+	suite := run.SuiteResult{
+		Total:   1,
+		XFailed: 1,
+		Results: []run.TestResult{
+			{
+				ID: "checkout.test.md", Status: run.TestXFail, Reason: "xfail: coupon bug #12",
+				Cases: []run.CaseResult{
+					{Name: "add to cart", Status: run.TestPass},
+					{Name: "apply coupon", Status: run.TestFail, Reason: "got 15%"},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, suite, ""); err != nil {
+		t.Fatalf("WriteMarkdown returned error: %v", err)
+	}
+	got := buf.String()
+	front, _, _ := strings.Cut(strings.TrimPrefix(got, "---\n"), "\n---\n")
+	var fm markdownFrontMatter
+	if err := yaml.Unmarshal([]byte(front), &fm); err != nil {
+		t.Fatalf("front matter is not YAML: %v", err)
+	}
+	if cases := fm.Cases[0].Cases; cases[0].Status != "pass" || cases[1].Status != "xfail" {
+		t.Fatalf("front matter cases = %+v, want the failing case xfail", cases)
+	}
+	if want := "- " + statusLabels[run.TestXFail] + " apply coupon: got 15%"; !strings.Contains(got, want) {
+		t.Fatalf("report missing %q:\n%s", want, got)
+	}
+	if strings.Contains(got, statusLabels[run.TestFail]) {
+		t.Fatalf("report shows a failure for an expected failure:\n%s", got)
+	}
+}
@@ -0,0 +1,225 @@
+package report
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// renderMarkdown converts the Markdown agents write in logs to HTML. It
+// covers headings, paragraphs, lists, block quotes, rules, fenced code,
+// pipe tables, and inline code, emphasis, links and images; anything else
+// is shown as text. rewrite maps link and image targets, e.g. to copies of
+// artifacts.
+func renderMarkdown(src string, rewrite func(target string) string) string {
+	r := &mdRenderer{rewrite: rewrite}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); {
+		i = r.block(lines, i)
+	}
+	return r.b.String()
+}
+
+type mdRenderer struct {
+	b       strings.Builder
+	rewrite func(string) string
+}
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedPattern  = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	rulePattern     = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	tableSepPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// block renders the block starting at lines[i] and returns the index of
+// the next unrendered line.
+func (r *mdRenderer) block(lines []string, i int) int {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		return i + 1
+	case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+		fence := trimmed[:3]
+		lang := strings.TrimSpace(trimmed[3:])
+		j := i + 1
+		var code []string
+		for ; j < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[j]), fence); j++ {
+			code = append(code, lines[j])
+		}
+		if lang != "" {
+			r.b.WriteString(`<pre><code class="language-` + html.EscapeString(lang) + `">`)
+		} else {
+			r.b.WriteString("<pre><code>")
+		}
+		r.b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+		r.b.WriteString("</code></pre>\n")
+		return j + 1
+	case headingPattern.MatchString(line):
+		match := headingPattern.FindStringSubmatch(line)
+		level := string(rune('0' + len(match[1])))
+		r.b.WriteString("<h" + level + ">" + r.inline(match[2]) + "</h" + level + ">\n")
+		return i + 1
+	case rulePattern.MatchString(line):
+		r.b.WriteString("<hr>\n")
+		return i + 1
+	case strings.HasPrefix(trimmed, ">"):
+		var quoted []string
+		j := i
+		for ; j < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[j]), ">"); j++ {
+			quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[j]), ">"), " "))
+		}
+		r.b.WriteString("<blockquote>\n" + renderMarkdown(strings.Join(quoted, "\n"), r.rewrite) + "</blockquote>\n")
+		return j
+	case bulletPattern.MatchString(line):
+		return r.list(lines, i, bulletPattern, "ul")
+	case orderedPattern.MatchString(line):
+		return r.list(lines, i, orderedPattern, "ol")
+	case strings.Contains(line, "|") && i+1 < len(lines) && tableSepPattern.MatchString(lines[i+1]):
+		return r.table(lines, i)
+	}
+
+	var para []string
+	j := i
+	for ; j < len(lines); j++ {
+		t := strings.TrimSpace(lines[j])
+		if t == "" || (j > i && startsBlock(lines[j])) {
+			break
+		}
+		para = append(para, t)
+	}
+	r.b.WriteString("<p>" + r.inline(strings.Join(para, "\n")) + "</p>\n")
+	return j
+}
+
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") ||
+		strings.HasPrefix(trimmed, ">") || headingPattern.MatchString(line) ||
+		bulletPattern.MatchString(line) || orderedPattern.MatchString(line)
+}
+
+func (r *mdRenderer) list(lines []string, i int, item *regexp.Regexp, tag string) int {
+	r.b.WriteString("<" + tag + ">\n")
+	j := i
+	for j < len(lines) {
+		match := item.FindStringSubmatch(lines[j])
+		if match == nil {
+			break
+		}
+		text := match[1]
+		j++
+		// Indented lines continue the item.
+		for j < len(lines) && strings.TrimSpace(lines[j]) != "" && !item.MatchString(lines[j]) &&
+			(strings.HasPrefix(lines[j], " ") || strings.HasPrefix(lines[j], "\t")) {
+			text += "\n" + strings.TrimSpace(lines[j])
+			j++
+		}
+		r.b.WriteString("<li>" + r.inline(text) + "</li>\n")
+	}
+	r.b.WriteString("</" + tag + ">\n")
+	return j
+}
+
+func (r *mdRenderer) table(lines []string, i int) int {
+	r.b.WriteString("<table>\n<thead><tr>")
+	for _, cell := range tableCells(lines[i]) {
+		r.b.WriteString("<th>" + r.inline(cell) + "</th>")
+	}
+	r.b.WriteString("</tr></thead>\n<tbody>\n")
+	j := i + 2
+	for ; j < len(lines) && strings.Contains(lines[j], "|") && strings.TrimSpace(lines[j]) != ""; j++ {
+		r.b.WriteString("<tr>")
+		for _, cell := range tableCells(lines[j]) {
+			r.b.WriteString("<td>" + r.inline(cell) + "</td>")
+		}
+		r.b.WriteString("</tr>\n")
+	}
+	r.b.WriteString("</tbody>\n</table>\n")
+	return j
+}
+
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	var cells []string
+	var cell strings.Builder
+	for k := 0; k < len(line); k++ {
+		if line[k] == '\\' && k+1 < len(line) && line[k+1] == '|' {
+			cell.WriteByte('|')
+			k++
+			continue
+		}
+		if line[k] == '|' {
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(line[k])
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+var (
+	codeSpanPattern = regexp.MustCompile("`([^`]+)`")
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	strongPattern   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emPattern       = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+)
+
+// inline renders inline Markdown. Code spans are cut out first so their
+// content is not formatted.
+func (r *mdRenderer) inline(text string) string {
+	var codes []string
+	text = codeSpanPattern.ReplaceAllStringFunc(text, func(m string) string {
+		codes = append(codes, "<code>"+html.EscapeString(m[1:len(m)-1])+"</code>")
+		return "\x00" + string(rune('a'+len(codes)-1)) + "\x00"
+	})
+	text = html.EscapeString(text)
+	text = imagePattern.ReplaceAllStringFunc(text, func(m string) string {
+		match := imagePattern.FindStringSubmatch(m)
+		return `<img src="` + r.target(match[2]) + `" alt="` + match[1] + `">`
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		match := linkPattern.FindStringSubmatch(m)
+		return `<a href="` + r.target(match[2]) + `">` + match[1] + `</a>`
+	})
+	text = strongPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emPattern.ReplaceAllString(text, "<em>$1$2</em>")
+	text = strings.ReplaceAll(text, "\n", "<br>\n")
+	for i, code := range codes {
+		text = strings.Replace(text, "\x00"+string(rune('a'+i))+"\x00", code, 1)
+	}
+	return text
+}
+
+// target rewrites an escaped link target and escapes the result again.
+func (r *mdRenderer) target(escaped string) string {
+	raw := html.UnescapeString(escaped)
+	if r.rewrite != nil {
+		raw = r.rewrite(raw)
+	}
+	return html.EscapeString(safeHref(raw))
+}
+
+// safeHref returns target if it is an http, https or mailto URL or a
+// relative reference, and "#" otherwise. Like a browser, it ignores
+// surrounding whitespace and control characters, tabs and newlines, and
+// the case of the scheme.
+func safeHref(target string) string {
+	target = strings.TrimFunc(target, func(r rune) bool { return r <= ' ' })
+	target = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(target)
+	end := strings.IndexAny(target, ":/?#")
+	if end < 0 || target[end] != ':' {
+		return target
+	}
+	switch strings.ToLower(target[:end]) {
+	case "http", "https", "mailto":
+		return target
+	}
+	return "#"
+}
@@ -0,0 +1,135 @@
+// Package report writes run results to files in CI-friendly formats.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+// Format names a --report output format.
+type Format string
+
+const (
+	FormatJUnit    Format = "junit"
+	FormatMarkdown Format = "markdown"
+	// FormatHTML writes a static site; its path is a directory.
+	FormatHTML Format = "html"
+)
+
+// caseStatus returns the status and reason to report for case c of result.
+// An xfail file's expectation covers its cases: failing cases of an
+// expected failure are expected failures, and an unexpected pass makes
+// every case one.
+func caseStatus(result run.TestResult, c run.CaseResult) (run.TestStatus, string) {
+	switch {
+	case result.Status == run.TestXFail && c.Status != run.TestPass:
+		return run.TestXFail, c.Reason
+	case result.Status == run.TestXPass:
+		return run.TestXPass, result.Reason
+	}
+	return c.Status, c.Reason
+}
+
+// Spec is one --report format=path flag.
+type Spec struct {
+	Format Format
+	Path   string
+}
+
+type InvalidSpecError struct {
+	Raw    string
+	Reason string
+}
+
+func (e *InvalidSpecError) Error() string {
+	return fmt.Sprintf("invalid report %q: %s (expected format=path, format one of junit, markdown or html)", e.Raw, e.Reason)
+}
+
+func ParseSpec(raw string) (Spec, error) {
+	name, path, ok := strings.Cut(raw, "=")
+	if !ok {
+		return Spec{}, &InvalidSpecError{Raw: raw, Reason: "missing ="}
+	}
+	format := Format(strings.TrimSpace(strings.ToLower(name)))
+	switch format {
+	case FormatJUnit, FormatMarkdown, FormatHTML:
+	default:
+		return Spec{}, &InvalidSpecError{Raw: raw, Reason: fmt.Sprintf("unknown format %q", name)}
+	}
+	if strings.TrimSpace(path) == "" {
+		return Spec{}, &InvalidSpecError{Raw: raw, Reason: "empty path"}
+	}
+	return Spec{Format: format, Path: path}, nil
+}
+
+// Write writes suite in the format of every spec.
+func Write(specs []Spec, suite run.SuiteResult) error {
+	for _, spec := range specs {
+		if spec.Format == FormatHTML {
+			if err := WriteHTML(spec.Path, suite); err != nil {
+				return fmt.Errorf("write %s report %s: %w", spec.Format, spec.Path, err)
+			}
+			continue
+		}
+		if err := writeFile(spec.Path, func(w io.Writer) error {
+			switch spec.Format {
+			case FormatJUnit:
+				return WriteJUnit(w, suite)
+			case FormatMarkdown:
+				dir, err := filepath.Abs(filepath.Dir(spec.Path))
+				if err != nil {
+					return err
+				}
+				return WriteMarkdown(w, suite, dir)
+			default:
+				return fmt.Errorf("unsupported report format %q", spec.Format)
+			}
+		}); err != nil {
+			return fmt.Errorf("write %s report %s: %w", spec.Format, spec.Path, err)
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// FileReporter is a run.Reporter that writes reports when the suite
+// finishes. Err returns the first write error.
+type FileReporter struct {
+	run.BaseReporter
+	specs []Spec
+	err   error
+}
+
+// NewFileReporter returns a FileReporter that writes specs.
+func NewFileReporter(specs []Spec) *FileReporter {
+	return &FileReporter{specs: specs}
+}
+
+// SuiteFinished writes every report for suite.
+func (r *FileReporter) SuiteFinished(suite run.SuiteResult) {
+	r.err = Write(r.specs, suite)
+}
+
+// Err returns the error from writing, if any.
+func (r *FileReporter) Err() error {
+	return r.err
+}
@@ -0,0 +1,78 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+)
+
+// CaseResult is the outcome of one named case of a multi-case test file.
+type CaseResult struct {
+	Name   string
+	Status TestStatus
+	Reason string
+}
+
+// resolveCaseNames fills CaseNames of every case whose file declares cases:.
+// Section headings are read from the final body, after includes and
+// variables.
+func resolveCaseNames(ids []string, cases map[string]TestCase) error {
+	for _, id := range ids {
+		tc := cases[id]
+		names, err := tc.File.Meta.Cases.Resolve(tc.Body)
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		tc.CaseNames = names
+		cases[id] = tc
+	}
+	return nil
+}
+
+// caseResults matches the declared case names with the cases: entries of
+// the log. Without a usable log every case fails with the test's reason.
+func caseResults(names []string, outcome agentOutcome) []CaseResult {
+	reported := make(map[string]logs.Step, len(outcome.Log.Cases))
+	for _, entry := range outcome.Log.Cases {
+		reported[caseKey(entry.Name)] = entry
+	}
+
+	results := make([]CaseResult, 0, len(names))
+	for _, name := range names {
+		result := CaseResult{Name: name, Status: TestFail}
+		entry, ok := reported[caseKey(name)]
+		switch {
+		case outcome.ParseErr != nil:
+			result.Reason = outcome.reason()
+		case !ok:
+			result.Reason = "not reported under cases: in the log"
+		case entry.Status == logs.StatusPass:
+			result.Status = TestPass
+		case entry.Status == logs.StatusFail:
+			result.Reason = entry.Reason
+			if result.Reason == "" {
+				result.Reason = "status=fail"
+			}
+		default:
+			result.Reason = "missing or invalid case status in the log"
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func caseKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// failedCases counts the cases of results that did not pass.
+func failedCases(results []CaseResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Status != TestPass {
+			failed++
+		}
+	}
+	return failed
+}
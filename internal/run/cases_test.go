@@ -0,0 +1,88 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunReportsSectionCasesAsSubResults(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "checkout.test.md"), "---\ncases: sections\n---\n## Add to cart\nClick.\n## Apply coupon\nType.\n## Pay\nPay.\n")
+
+	var gotCases []string
+	var out strings.Builder
+	deps := Dependencies{
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			logDir := filepath.Join(filepath.Dir(testAbs), "checkout.logs")
+			return logDir, filepath.Join(logDir, "x.log.md"), nil
+		},
+		ParseLog: func(string) (logs.Log, error) {
+			return logs.Log{
+				Status: logs.StatusPass,
+				Cases: []logs.Step{
+					{Name: "add to cart", Status: logs.StatusPass},
+					{Name: "Apply coupon", Status: logs.StatusFail, Reason: "got 15%"},
+				},
+			}, nil
+		},
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			gotCases = in.Cases
+			return "prompt", nil
+		},
+		MkdirAll: os.MkdirAll,
+		Now:      time.Now,
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			return ExecResult{}, nil
+		},
+		Out: &out,
+	}
+
+	suite, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	wantNames := []string{"Add to cart", "Apply coupon", "Pay"}
+	if !reflect.DeepEqual(gotCases, wantNames) {
+		t.Fatalf("prompt cases = %#v, want %#v", gotCases, wantNames)
+	}
+	result := suite.Results[0]
+	wantCases := []CaseResult{
+		{Name: "Add to cart", Status: TestPass},
+		{Name: "Apply coupon", Status: TestFail, Reason: "got 15%"},
+		{Name: "Pay", Status: TestFail, Reason: "not reported under cases: in the log"},
+	}
+	if !reflect.DeepEqual(result.Cases, wantCases) {
+		t.Fatalf("Cases = %#v, want %#v", result.Cases, wantCases)
+	}
+	if result.Status != TestFail || !strings.HasPrefix(result.Reason, "2 of 3 cases failed") {
+		t.Fatalf("result = %q/%q, want failure from cases", result.Status, result.Reason)
+	}
+	if suite.CaseTotal != 3 || suite.CaseFailed != 2 {
+		t.Fatalf("case counts = %d/%d, want 3/2", suite.CaseTotal, suite.CaseFailed)
+	}
+	for _, want := range []string{`checkout.test.md: case "Apply coupon" failed: got 15%`, "Cases passed: 1/3"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output = %q, want %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunRejectsDuplicateCaseNames(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "---\ncases: [login, Login]\n---\n")
+
+	_, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, Dependencies{Out: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "duplicate case") {
+		t.Fatalf("Run error = %v, want duplicate case setup error", err)
+	}
+}
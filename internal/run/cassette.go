@@ -0,0 +1,218 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// Cassette is the recording of one agent invocation. Paths of the suite root
+// and of the log in Argv and Prompt are replaced with ${ROOT} and ${LOG},
+// and those of files named after the log, such as a rendered test, with
+// ${LOG_STEM}, so recordings compare across machines and runs.
+type Cassette struct {
+	ID      string   `json:"id"`
+	Attempt int      `json:"attempt"`
+	Argv    []string `json:"argv"`
+	Prompt  string   `json:"prompt"`
+	// Output is the agent's stdout and stderr, interleaved as written. They
+	// are kept as one stream because that is what the agent produces: in
+	// interactive mode both go to one terminal, and otherwise reporters and
+	// the transcript receive them through one writer. Splitting them would
+	// lose their relative order, which replay reproduces.
+	Output   string `json:"output"`
+	ExitCode int    `json:"exit_code"`
+	// Log is the log the agent left, nil when it wrote none.
+	Log *string `json:"log"`
+}
+
+// MissingCassetteError is returned when replaying an invocation that was
+// never recorded.
+type MissingCassetteError struct {
+	ID      string
+	Attempt int
+	Path    string
+}
+
+func (e *MissingCassetteError) Error() string {
+	return fmt.Sprintf("no recording of %s attempt %d (expected %s); record it again with --record", e.ID, e.Attempt, e.Path)
+}
+
+// CassetteMismatchError is returned when replaying an invocation whose
+// prompt or argv differ from the recording, e.g. because the test, the
+// prompt template or the agent changed since it was recorded.
+type CassetteMismatchError struct {
+	ID      string
+	Attempt int
+	Path    string
+	// Field is "prompt" or "argv".
+	Field string
+	// Diff describes the first difference.
+	Diff string
+}
+
+func (e *CassetteMismatchError) Error() string {
+	return fmt.Sprintf("recording of %s attempt %d (%s) has a different %s, %s; record it again with --record", e.ID, e.Attempt, e.Path, e.Field, e.Diff)
+}
+
+// CassettePath returns the file that records attempt of the test or hook
+// id: checkout/pay.test.md attempt 1 is checkout/pay.test.md.1.json. Matrix
+// parameters are escaped so that they stay in the file name.
+func CassettePath(dir string, id string, attempt int) string {
+	if i := strings.IndexByte(id, '['); i >= 0 {
+		id = id[:i] + testfile.EscapePath(id[i:])
+	}
+	return filepath.Join(dir, filepath.FromSlash(id)+fmt.Sprintf(".%d.json", attempt))
+}
+
+// RecordExec returns an ExecFunc that runs exec and saves each invocation
+// to a cassette in dir, replacing an earlier recording of it.
+func RecordExec(dir string, exec ExecFunc) ExecFunc {
+	return func(ctx context.Context, req ExecRequest) (ExecResult, error) {
+		var output bytes.Buffer
+		if req.Transcript != nil {
+			req.Transcript = io.MultiWriter(req.Transcript, &output)
+		} else {
+			req.Transcript = &output
+		}
+		result, err := exec(ctx, req)
+		if err != nil {
+			return result, err
+		}
+
+		cassette := Cassette{
+			ID:       req.ID,
+			Attempt:  req.Attempt,
+			Prompt:   normalizePaths(req.Prompt, req),
+			Output:   output.String(),
+			ExitCode: result.ExitCode,
+		}
+		for _, arg := range req.Argv {
+			cassette.Argv = append(cassette.Argv, normalizePaths(arg, req))
+		}
+		content, err := os.ReadFile(req.LogAbs)
+		switch {
+		case err == nil:
+			log := string(content)
+			cassette.Log = &log
+		case !errors.Is(err, os.ErrNotExist):
+			return result, fmt.Errorf("record %s: %w", req.ID, err)
+		}
+		if err := writeCassette(CassettePath(dir, req.ID, req.Attempt), cassette); err != nil {
+			return result, fmt.Errorf("record %s: %w", req.ID, err)
+		}
+		return result, nil
+	}
+}
+
+// ReplayExec returns an ExecFunc that runs no agent and instead plays back
+// the cassettes RecordExec saved in dir: it prints the recorded output,
+// writes the recorded log to the new log path, and returns the recorded
+// exit code. Invocations are matched by test ID and attempt, and fail with
+// a *CassetteMismatchError when their normalized prompt or argv differ from
+// the recording.
+func ReplayExec(dir string) ExecFunc {
+	return func(_ context.Context, req ExecRequest) (ExecResult, error) {
+		path := CassettePath(dir, req.ID, req.Attempt)
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return ExecResult{}, &MissingCassetteError{ID: req.ID, Attempt: req.Attempt, Path: path}
+		}
+		if err != nil {
+			return ExecResult{}, fmt.Errorf("replay %s: %w", req.ID, err)
+		}
+		var cassette Cassette
+		if err := json.Unmarshal(content, &cassette); err != nil {
+			return ExecResult{}, fmt.Errorf("replay %s: %s: %w", req.ID, path, err)
+		}
+		if err := matchCassette(cassette, req, path); err != nil {
+			return ExecResult{}, err
+		}
+
+		for _, w := range []io.Writer{req.Output, req.Transcript} {
+			if w != nil {
+				_, _ = io.WriteString(w, cassette.Output)
+			}
+		}
+		if cassette.Log != nil {
+			if err := os.WriteFile(req.LogAbs, []byte(*cassette.Log), 0o644); err != nil {
+				return ExecResult{}, fmt.Errorf("replay %s: %w", req.ID, err)
+			}
+		}
+		return ExecResult{ExitCode: cassette.ExitCode}, nil
+	}
+}
+
+// matchCassette checks that req is the invocation cassette recorded.
+func matchCassette(cassette Cassette, req ExecRequest, path string) error {
+	mismatch := func(field, diff string) error {
+		return &CassetteMismatchError{ID: req.ID, Attempt: req.Attempt, Path: path, Field: field, Diff: diff}
+	}
+	if prompt := normalizePaths(req.Prompt, req); prompt != cassette.Prompt {
+		return mismatch("prompt", firstLineDiff(cassette.Prompt, prompt))
+	}
+	argv := make([]string, 0, len(req.Argv))
+	for _, arg := range req.Argv {
+		argv = append(argv, normalizePaths(arg, req))
+	}
+	for i := range min(len(argv), len(cassette.Argv)) {
+		if argv[i] != cassette.Argv[i] {
+			return mismatch("argv", fmt.Sprintf("argument %d: recorded %q, got %q", i, cassette.Argv[i], argv[i]))
+		}
+	}
+	if len(argv) != len(cassette.Argv) {
+		return mismatch("argv", fmt.Sprintf("recorded %d arguments, got %d", len(cassette.Argv), len(argv)))
+	}
+	return nil
+}
+
+// firstLineDiff describes the first line where got differs from recorded.
+func firstLineDiff(recorded, got string) string {
+	a, b := strings.Split(recorded, "\n"), strings.Split(got, "\n")
+	for i := range min(len(a), len(b)) {
+		if a[i] != b[i] {
+			return fmt.Sprintf("line %d: recorded %q, got %q", i+1, a[i], b[i])
+		}
+	}
+	// One is a prefix of the other; show the first extra line.
+	i := min(len(a), len(b))
+	if len(a) > i {
+		return fmt.Sprintf("line %d: recorded %q, got no line", i+1, a[i])
+	}
+	return fmt.Sprintf("line %d: recorded no line, got %q", i+1, b[i])
+}
+
+// normalizePaths replaces the log path and suite root in s with ${LOG} and
+// ${ROOT}, and the log path without its .log.md suffix, which names the
+// files written next to the log, with ${LOG_STEM}.
+func normalizePaths(s string, req ExecRequest) string {
+	if req.LogAbs != "" {
+		s = strings.ReplaceAll(s, req.LogAbs, "${LOG}")
+		if stem := strings.TrimSuffix(req.LogAbs, ".log.md"); stem != req.LogAbs {
+			s = strings.ReplaceAll(s, stem, "${LOG_STEM}")
+		}
+	}
+	if req.RootAbs != "" {
+		s = strings.ReplaceAll(s, req.RootAbs, "${ROOT}")
+	}
+	return s
+}
+
+func writeCassette(path string, cassette Cassette) error {
+	content, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
@@ -0,0 +1,149 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunRecordsAndReplaysAgentInvocations(t *testing.T) {
+	root := t.TempDir()
+	cassettes := filepath.Join(t.TempDir(), "cassettes")
+	mustWriteFile(t, filepath.Join(root, "shop", "pay.test.md"), "# Pay\n")
+
+	calls := 0
+	exec := func(_ context.Context, req ExecRequest) (ExecResult, error) {
+		calls++
+		_, _ = fmt.Fprintf(req.Transcript, "attempt %d\n", req.Attempt)
+		if req.Attempt == 1 {
+			// The first attempt forgets the log, so it gets repaired.
+			return ExecResult{ExitCode: 1}, nil
+		}
+		return ExecResult{}, os.WriteFile(req.LogAbs, []byte("---\nstatus: fail\nreason: declined\n---\n\nCard declined.\n"), 0o644)
+	}
+	cfg := Config{Root: root, Agent: agent.ClaudeAgent, RepairLimit: 1, RecordDir: cassettes}
+	recorded, err := Run(context.Background(), cfg, DefaultDependencies(io.Discard, exec))
+	if err != nil {
+		t.Fatalf("record Run returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("agent calls = %d, want 2", calls)
+	}
+
+	content, err := os.ReadFile(CassettePath(cassettes, "shop/pay.test.md", 1))
+	if err != nil {
+		t.Fatalf("read first cassette: %v", err)
+	}
+	var first Cassette
+	if err := json.Unmarshal(content, &first); err != nil {
+		t.Fatalf("decode cassette: %v", err)
+	}
+	if first.ID != "shop/pay.test.md" || first.Attempt != 1 || first.ExitCode != 1 || first.Log != nil || first.Output != "attempt 1\n" {
+		t.Fatalf("first cassette = %#v", first)
+	}
+	if !strings.Contains(first.Prompt, "${LOG}") || strings.Contains(first.Prompt, root) {
+		t.Fatalf("prompt = %q, want paths replaced by placeholders", first.Prompt)
+	}
+	if first.Argv[0] != "claude" || first.Argv[len(first.Argv)-1] != first.Prompt {
+		t.Fatalf("argv = %#v, want the command with the normalized prompt", first.Argv)
+	}
+	if _, err := os.Stat(CassettePath(cassettes, "shop/pay.test.md", 2)); err != nil {
+		t.Fatalf("second cassette: %v", err)
+	}
+
+	cfg.RecordDir, cfg.ReplayDir = "", cassettes
+	failExec := func(context.Context, ExecRequest) (ExecResult, error) {
+		return ExecResult{}, errors.New("agent must not run during replay")
+	}
+	replayed, err := Run(context.Background(), cfg, DefaultDependencies(io.Discard, failExec))
+	if err != nil {
+		t.Fatalf("replay Run returned error: %v", err)
+	}
+	got, want := replayed.Results[0], recorded.Results[0]
+	if got.Status != want.Status || got.Reason != want.Reason || got.Repairs != 1 {
+		t.Fatalf("replayed result = %q %q after %d repairs, want %q %q after 1", got.Status, got.Reason, got.Repairs, want.Status, want.Reason)
+	}
+	transcript, err := os.ReadFile(got.TranscriptAbs)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	if !strings.Contains(string(transcript), "attempt 1\n") || !strings.Contains(string(transcript), "attempt 2\n") {
+		t.Fatalf("transcript = %q, want the recorded output", transcript)
+	}
+}
+
+func TestRunReplayReportsMissingCassette(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+
+	_, err := Run(context.Background(), Config{Root: root, Agent: agent.CodexAgent, ReplayDir: t.TempDir()}, DefaultDependencies(io.Discard, nil))
+	var missing *MissingCassetteError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Run error = %v, want *MissingCassetteError", err)
+	}
+	if missing.ID != "a.test.md" || missing.Attempt != 1 {
+		t.Fatalf("missing = %#v", missing)
+	}
+}
+
+func TestRunReplayChecksPromptAgainstRecording(t *testing.T) {
+	root := t.TempDir()
+	cassettes := t.TempDir()
+	// Matrix cases are handed to the agent as a rendered copy next to the
+	// log, whose timestamped path must not count as a change.
+	mustWriteFile(t, filepath.Join(root, "pay.test.md"), "---\nmatrix:\n  card: [visa]\n---\n# Pay with ${card}\n")
+	exec := func(_ context.Context, req ExecRequest) (ExecResult, error) {
+		return ExecResult{}, os.WriteFile(req.LogAbs, []byte("---\nstatus: pass\n---\n\nPaid.\n"), 0o644)
+	}
+	cfg := Config{Root: root, Agent: agent.ClaudeAgent, RecordDir: cassettes}
+	if _, err := Run(context.Background(), cfg, DefaultDependencies(io.Discard, exec)); err != nil {
+		t.Fatalf("record Run returned error: %v", err)
+	}
+	var cassette Cassette
+	content, err := os.ReadFile(CassettePath(cassettes, "pay.test.md[card=visa]", 1))
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	if err := json.Unmarshal(content, &cassette); err != nil {
+		t.Fatalf("decode cassette: %v", err)
+	}
+	if !strings.Contains(cassette.Prompt, "${LOG_STEM}.input.md") {
+		t.Fatalf("prompt = %q, want the rendered test path normalized", cassette.Prompt)
+	}
+
+	cfg.RecordDir, cfg.ReplayDir = "", cassettes
+	if _, err := Run(context.Background(), cfg, DefaultDependencies(io.Discard, nil)); err != nil {
+		t.Fatalf("replay Run returned error: %v", err)
+	}
+
+	deps := DefaultDependencies(io.Discard, nil)
+	deps.BuildPrompt = func(in prompt.Input) (string, error) {
+		text, err := prompt.Render(in)
+		return text + "\nAlso check the receipt.", err
+	}
+	_, err = Run(context.Background(), cfg, deps)
+	var mismatch *CassetteMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Run error = %v, want *CassetteMismatchError", err)
+	}
+	if mismatch.Field != "prompt" || !strings.Contains(mismatch.Diff, "Also check the receipt.") {
+		t.Fatalf("mismatch = %#v, want the changed prompt line", mismatch)
+	}
+}
+
+func TestRunRejectsRecordWithReplay(t *testing.T) {
+	_, err := Run(context.Background(), Config{Root: t.TempDir(), Agent: agent.ClaudeAgent, RecordDir: "a", ReplayDir: "b"}, Dependencies{})
+	var setupErr *SetupError
+	if !errors.As(err, &setupErr) {
+		t.Fatalf("Run error = %v, want *SetupError", err)
+	}
+}
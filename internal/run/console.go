@@ -0,0 +1,296 @@
+package run
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConsoleMode selects how the console reporter shows a run.
+type ConsoleMode string
+
+const (
+	// ConsoleProgress redraws a status line for each running test and
+	// colors results. It needs a terminal.
+	ConsoleProgress ConsoleMode = "progress"
+	// ConsolePlain prints one uncolored line per event, for logs and pipes.
+	ConsolePlain ConsoleMode = "plain"
+)
+
+// NewConsoleReporter returns a reporter that prints each test as it
+// completes, prefixes agent output with the test ID, and ends with the
+// same failure details and summary line as the default reporter.
+func NewConsoleReporter(out io.Writer, mode ConsoleMode) Reporter {
+	return &consoleReporter{out: out, mode: mode, summary: NewSummaryReporter(out), now: time.Now}
+}
+
+const (
+	ansiClearLine = "\r\x1b[2K"
+	ansiClearDown = "\x1b[J"
+	ansiDim       = "\x1b[2m"
+	ansiRed       = "\x1b[31m"
+	ansiGreen     = "\x1b[32m"
+	ansiYellow    = "\x1b[33m"
+	ansiReset     = "\x1b[0m"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+type consoleReporter struct {
+	BaseReporter
+	out     io.Writer
+	mode    ConsoleMode
+	summary Reporter
+	now     func() time.Time
+
+	mu sync.Mutex
+	// running holds the invocations shown on the status lines by test ID.
+	running map[string]runningAgent
+	// drawn is the number of status lines on screen.
+	drawn int
+	frame int
+	// partial holds agent output after the last newline.
+	partial   bytes.Buffer
+	partialID string
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type runningAgent struct {
+	AgentStart
+	started time.Time
+}
+
+func (r *consoleReporter) AgentStarted(start AgentStart) {
+	r.stopTicker()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushPartial()
+	if r.running == nil {
+		r.running = make(map[string]runningAgent)
+	}
+	r.running[start.ID] = runningAgent{AgentStart: start, started: r.now()}
+	if r.mode != ConsoleProgress {
+		line := fmt.Sprintf("RUN   %s (%s", start.ID, start.Agent)
+		if start.Attempt > 1 {
+			line += fmt.Sprintf(", attempt %d", start.Attempt)
+		}
+		_, _ = fmt.Fprintln(r.out, line+")")
+		return
+	}
+	r.drawStatus()
+	r.startTicker()
+}
+
+// startTicker animates the status lines. The caller holds mu.
+func (r *consoleReporter) startTicker() {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.tick(r.stop, r.done)
+}
+
+func (r *consoleReporter) tick(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			r.frame++
+			r.drawStatus()
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *consoleReporter) stopTicker() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop, r.done = nil, nil
+}
+
+// drawStatus redraws one status line per running test, in the order they
+// started, over the lines drawn before. The caller holds mu.
+func (r *consoleReporter) drawStatus() {
+	if r.mode != ConsoleProgress {
+		return
+	}
+	if len(r.running) == 0 {
+		r.clearStatus()
+		return
+	}
+	agents := make([]runningAgent, 0, len(r.running))
+	for _, a := range r.running {
+		agents = append(agents, a)
+	}
+	slices.SortFunc(agents, func(a, b runningAgent) int {
+		if c := a.started.Compare(b.started); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	var b strings.Builder
+	r.moveToStatusTop(&b)
+	for i, a := range agents {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		elapsed := r.now().Sub(a.started).Truncate(time.Second)
+		fmt.Fprintf(&b, "%s%s %s %s· %s · attempt %d · %s%s", ansiClearLine,
+			spinnerFrames[r.frame%len(spinnerFrames)], a.ID, ansiDim, a.Agent, a.Attempt, elapsed, ansiReset)
+	}
+	if len(agents) < r.drawn {
+		b.WriteString(ansiClearDown)
+	}
+	r.drawn = len(agents)
+	_, _ = io.WriteString(r.out, b.String())
+}
+
+// clearStatus removes the status lines so a full line can be printed. The
+// caller holds mu.
+func (r *consoleReporter) clearStatus() {
+	if r.drawn == 0 {
+		return
+	}
+	var b strings.Builder
+	r.moveToStatusTop(&b)
+	b.WriteString(ansiClearLine)
+	if r.drawn > 1 {
+		b.WriteString(ansiClearDown)
+	}
+	r.drawn = 0
+	_, _ = io.WriteString(r.out, b.String())
+}
+
+// moveToStatusTop moves the cursor from the last status line to the first.
+func (r *consoleReporter) moveToStatusTop(b *strings.Builder) {
+	if r.drawn > 1 {
+		fmt.Fprintf(b, "\x1b[%dA", r.drawn-1)
+	}
+}
+
+func (r *consoleReporter) AgentOutput(id string) io.Writer {
+	return &consoleAgentWriter{r: r, id: id}
+}
+
+type consoleAgentWriter struct {
+	r  *consoleReporter
+	id string
+}
+
+func (w *consoleAgentWriter) Write(p []byte) (int, error) {
+	r := w.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.partialID != w.id {
+		r.flushPartial()
+		r.partialID = w.id
+	}
+	r.partial.Write(p)
+	for {
+		line, err := r.partial.ReadString('\n')
+		if err != nil {
+			// Keep the incomplete line for the next write.
+			rest := line
+			r.partial.Reset()
+			r.partial.WriteString(rest)
+			break
+		}
+		r.printAgentLine(w.id, strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// flushPartial prints agent output left without a trailing newline. The
+// caller holds mu.
+func (r *consoleReporter) flushPartial() {
+	if r.partial.Len() > 0 {
+		r.printAgentLine(r.partialID, strings.TrimRight(r.partial.String(), "\r\n"))
+		r.partial.Reset()
+	}
+}
+
+func (r *consoleReporter) printAgentLine(id string, line string) {
+	if r.mode == ConsoleProgress {
+		r.clearStatus()
+		_, _ = fmt.Fprintf(r.out, "%s%s │%s %s\n", ansiDim, id, ansiReset, line)
+		r.drawStatus()
+		return
+	}
+	_, _ = fmt.Fprintf(r.out, "%s | %s\n", id, line)
+}
+
+func (r *consoleReporter) TestFinished(result TestResult) {
+	r.stopTicker()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushPartial()
+	r.clearStatus()
+	delete(r.running, result.ID)
+
+	detail := ""
+	if result.Elapsed > 0 {
+		detail = " (" + formatElapsed(result.Elapsed) + ")"
+	}
+	if result.Status != TestPass && result.Reason != "" {
+		detail += ": " + result.Reason
+	}
+	if r.mode != ConsoleProgress {
+		_, _ = fmt.Fprintf(r.out, "%-5s %s%s\n", strings.ToUpper(string(result.Status)), result.ID, detail)
+		return
+	}
+	symbol, color := "✗", ansiRed
+	switch result.Status {
+	case TestPass:
+		symbol, color = "✓", ansiGreen
+	case TestSkip:
+		symbol, color = "-", ansiYellow
+	case TestXFail:
+		symbol, color = "✗", ansiYellow
+	}
+	_, _ = fmt.Fprintf(r.out, "%s%s%s %s%s%s%s\n", color, symbol, ansiReset, result.ID, ansiDim, detail, ansiReset)
+	if len(r.running) > 0 {
+		// Other tests are still running; their lines follow the result.
+		r.drawStatus()
+		r.startTicker()
+	}
+}
+
+func (r *consoleReporter) SuiteFinished(suite SuiteResult) {
+	r.stopTicker()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushPartial()
+	r.clearStatus()
+	clear(r.running)
+	r.summary.SuiteFinished(suite)
+}
+
+// SuiteAborted stops the status lines, which agent invocations that failed
+// to run or were canceled leave spinning.
+func (r *consoleReporter) SuiteAborted(error) {
+	r.stopTicker()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushPartial()
+	r.clearStatus()
+	clear(r.running)
+}
+
+func formatElapsed(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
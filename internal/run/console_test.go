@@ -0,0 +1,154 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestConsoleReporterPlainPrintsEachTest(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "")
+
+	var out strings.Builder
+	deps := Dependencies{
+		DiscoverTests: func(string) ([]string, error) { return []string{"a.test.md", "b.test.md"}, nil },
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			base := strings.TrimSuffix(filepath.Base(testAbs), ".test.md")
+			logDir := filepath.Join(filepath.Dir(testAbs), base+".logs")
+			return logDir, filepath.Join(logDir, base+".log.md"), nil
+		},
+		ParseLog: func(logAbs string) (logs.Log, error) {
+			if strings.HasSuffix(logAbs, "a.log.md") {
+				return logs.Log{Status: logs.StatusPass}, nil
+			}
+			return logs.Log{Status: logs.StatusFail, Reason: "wrong total"}, nil
+		},
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         func() time.Time { return time.Date(2026, 2, 10, 14, 30, 0, 0, time.UTC) },
+		Exec: func(_ context.Context, req ExecRequest) (ExecResult, error) {
+			_, _ = io.WriteString(req.Output, "thinking\nclicking")
+			_, _ = io.WriteString(req.Output, " the button\n")
+			return ExecResult{}, nil
+		},
+		Out:       &out,
+		Reporters: []Reporter{NewConsoleReporter(&out, ConsolePlain)},
+	}
+
+	if _, err := Run(context.Background(), Config{Root: root, Agent: agent.CodexAgent}, deps); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want := "RUN   a.test.md (codex)\n" +
+		"a.test.md | thinking\n" +
+		"a.test.md | clicking the button\n" +
+		"PASS  a.test.md\n" +
+		"RUN   b.test.md (codex)\n" +
+		"b.test.md | thinking\n" +
+		"b.test.md | clicking the button\n" +
+		"FAIL  b.test.md: status=fail: wrong total (agent exit code 0)\n" +
+		"Total: 2, Passed: 1, Failed: 1\n"
+	if out.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Fatalf("plain output has escape codes: %q", out.String())
+	}
+}
+
+func TestConsoleReporterProgressRedrawsStatusLine(t *testing.T) {
+	var out strings.Builder
+	now := time.Date(2026, 2, 10, 14, 30, 0, 0, time.UTC)
+	r := NewConsoleReporter(&out, ConsoleProgress).(*consoleReporter)
+	r.now = func() time.Time { return now }
+
+	r.AgentStarted(AgentStart{ID: "a.test.md", Agent: agent.ClaudeAgent, Attempt: 2})
+	now = now.Add(3 * time.Second)
+	_, _ = io.WriteString(r.AgentOutput("a.test.md"), "hello\n")
+	r.TestFinished(TestResult{ID: "a.test.md", Status: TestPass, Elapsed: 3 * time.Second})
+	r.SuiteFinished(SuiteResult{Total: 1, Passed: 1})
+
+	got := out.String()
+	for _, want := range []string{
+		"\r\x1b[2K⠋ a.test.md \x1b[2m· claude · attempt 2 · 0s\x1b[0m",
+		"\r\x1b[2K\x1b[2ma.test.md │\x1b[0m hello\n",
+		"· attempt 2 · 3s",
+		"\r\x1b[2K\x1b[32m✓\x1b[0m a.test.md\x1b[2m (3s)\x1b[0m\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("output = %q, want %q", got, want)
+		}
+	}
+	if !strings.HasSuffix(got, "Total: 1, Passed: 1, Failed: 0\n") {
+		t.Fatalf("output = %q, want summary last", got)
+	}
+}
+
+func TestConsoleReporterProgressDrawsALinePerRunningTest(t *testing.T) {
+	var out strings.Builder
+	now := time.Date(2026, 2, 10, 14, 30, 0, 0, time.UTC)
+	r := NewConsoleReporter(&out, ConsoleProgress).(*consoleReporter)
+	r.now = func() time.Time { return now }
+
+	r.AgentStarted(AgentStart{ID: "a.test.md", Agent: agent.ClaudeAgent, Attempt: 1})
+	now = now.Add(time.Second)
+	r.AgentStarted(AgentStart{ID: "b.test.md", Agent: agent.ClaudeAgent, Attempt: 1})
+	r.stopTicker()
+	out.Reset()
+	_, _ = io.WriteString(r.AgentOutput("b.test.md"), "hello\n")
+
+	lineA := "\r\x1b[2K⠋ a.test.md \x1b[2m· claude · attempt 1 · 1s\x1b[0m"
+	lineB := "\r\x1b[2K⠋ b.test.md \x1b[2m· claude · attempt 1 · 0s\x1b[0m"
+	want := "\x1b[1A\r\x1b[2K\x1b[J" +
+		"\x1b[2mb.test.md │\x1b[0m hello\n" +
+		lineA + "\n" + lineB
+	if got := out.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	r.TestFinished(TestResult{ID: "a.test.md", Status: TestPass})
+	want = "\x1b[1A\r\x1b[2K\x1b[J" +
+		"\x1b[32m✓\x1b[0m a.test.md\x1b[2m\x1b[0m\n" +
+		lineB
+	if got := out.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	r.TestFinished(TestResult{ID: "b.test.md", Status: TestPass})
+	if r.stop != nil {
+		t.Fatal("status line ticker still running after every test finished")
+	}
+}
+
+func TestConsoleReporterProgressStopsWhenAgentFails(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+
+	var out strings.Builder
+	r := NewConsoleReporter(&out, ConsoleProgress).(*consoleReporter)
+	deps := reporterTestDeps(root, &out, r)
+	deps.Exec = func(context.Context, ExecRequest) (ExecResult, error) {
+		return ExecResult{}, errors.New("agent binary vanished")
+	}
+
+	if _, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, Files: []string{"a.test.md"}}, deps); err == nil {
+		t.Fatal("Run returned nil error, want setup error")
+	}
+	if r.stop != nil {
+		t.Fatal("status line ticker still running after the run aborted")
+	}
+	if !strings.HasSuffix(out.String(), ansiClearLine) {
+		t.Fatalf("output = %q, want the status line cleared", out.String())
+	}
+}
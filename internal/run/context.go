@@ -0,0 +1,65 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/config"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+// ContextFileName is a per-directory project context file added to the
+// prompt of every test in that directory and below.
+const ContextFileName = "MDTEST_CONTEXT.md"
+
+// loadContext collects context files from the suite root down to the
+// directory of stack's last entry. Within a directory, MDTEST_CONTEXT.md
+// comes before files listed under context: in mdtest.yaml.
+func loadContext(rootAbs string, stack config.Stack) ([]prompt.ContextFile, error) {
+	var files []prompt.ContextFile
+	seen := make(map[string]bool)
+	add := func(fileAbs string, raw string, optional bool) error {
+		rel, err := relInsideRoot(rootAbs, fileAbs, raw)
+		if err != nil {
+			return fmt.Errorf("context: %w", err)
+		}
+		rel = filepath.ToSlash(rel)
+		if seen[rel] {
+			return nil
+		}
+		content, err := os.ReadFile(fileAbs)
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("context: read %s: %w", rel, err)
+		}
+		seen[rel] = true
+		files = append(files, prompt.ContextFile{
+			Path:    rel,
+			Content: strings.TrimRight(string(content), "\r\n"),
+		})
+		return nil
+	}
+
+	for _, dir := range stack {
+		dirAbs := filepath.Join(rootAbs, filepath.FromSlash(dir.Path))
+		if err := add(filepath.Join(dirAbs, ContextFileName), path.Join(dir.Path, ContextFileName), true); err != nil {
+			return nil, err
+		}
+		for _, raw := range dir.Context {
+			fileAbs := filepath.FromSlash(raw)
+			if !filepath.IsAbs(fileAbs) {
+				fileAbs = filepath.Join(dirAbs, fileAbs)
+			}
+			if err := add(filepath.Clean(fileAbs), raw, false); err != nil {
+				return nil, fmt.Errorf("%s: %w", path.Join(dir.Path, config.FileName), err)
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,55 @@
+package run
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/internal/config"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestLoadContextOrdersRootToLeaf(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ContextFileName), "root context\n")
+	mustWriteFile(t, filepath.Join(root, "docs", "glossary.md"), "terms\n")
+	mustWriteFile(t, filepath.Join(root, "api", ContextFileName), "api context\n")
+
+	stack := config.Stack{
+		{Path: ".", Context: []string{"docs/glossary.md", ContextFileName}},
+		{Path: "api", Context: []string{"../docs/glossary.md"}},
+	}
+	got, err := loadContext(root, stack)
+	if err != nil {
+		t.Fatalf("loadContext returned error: %v", err)
+	}
+	want := []prompt.ContextFile{
+		{Path: ContextFileName, Content: "root context"},
+		{Path: "docs/glossary.md", Content: "terms"},
+		{Path: "api/" + ContextFileName, Content: "api context"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("context = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadContextRejectsMissingAndOutsideFiles(t *testing.T) {
+	root := t.TempDir()
+	tests := []struct {
+		name    string
+		context string
+		want    string
+	}{
+		{name: "missing", context: "nope.md", want: "nope.md"},
+		{name: "outside", context: "../secret.md", want: "outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadContext(root, config.Stack{{Path: ".", Context: []string{tt.context}}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("loadContext error = %v, want mention of %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -61,32 +61,11 @@ func ResolveExplicitTests(rootAbs string, files []string) ([]string, error) {
 	unique := make(map[string]struct{}, len(files))
 
 	for _, raw := range files {
-		targetAbs := raw
-		if !filepath.IsAbs(targetAbs) {
-			targetAbs = filepath.Join(rootAbs, targetAbs)
-		}
-		targetAbs = filepath.Clean(targetAbs)
-
-		rel, err := filepath.Rel(rootAbs, targetAbs)
-		if err != nil {
-			return nil, fmt.Errorf("resolve %q relative to root: %w", raw, err)
-		}
-		if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) || filepath.IsAbs(rel) {
-			return nil, fmt.Errorf("file %q is outside root %s", raw, rootAbs)
-		}
-		if !strings.HasSuffix(targetAbs, ".test.md") {
-			return nil, fmt.Errorf("file %q must end with .test.md", raw)
-		}
-
-		info, err := os.Stat(targetAbs)
+		rel, err := resolveTestPath(rootAbs, raw)
 		if err != nil {
-			return nil, fmt.Errorf("stat file %q: %w", raw, err)
+			return nil, err
 		}
-		if !info.Mode().IsRegular() {
-			return nil, fmt.Errorf("file %q is not a regular file", raw)
-		}
-
-		unique[filepath.ToSlash(rel)] = struct{}{}
+		unique[rel] = struct{}{}
 	}
 
 	tests := make([]string, 0, len(unique))
@@ -97,6 +76,46 @@ func ResolveExplicitTests(rootAbs string, files []string) ([]string, error) {
 	return tests, nil
 }
 
+// resolveTestPath validates one test path, relative to rootAbs unless
+// absolute, and returns its POSIX-style path relative to rootAbs.
+func resolveTestPath(rootAbs string, raw string) (string, error) {
+	targetAbs := raw
+	if !filepath.IsAbs(targetAbs) {
+		targetAbs = filepath.Join(rootAbs, targetAbs)
+	}
+	targetAbs = filepath.Clean(targetAbs)
+
+	rel, err := relInsideRoot(rootAbs, targetAbs, raw)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(targetAbs, ".test.md") {
+		return "", fmt.Errorf("file %q must end with .test.md", raw)
+	}
+
+	info, err := os.Stat(targetAbs)
+	if err != nil {
+		return "", fmt.Errorf("stat file %q: %w", raw, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("file %q is not a regular file", raw)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// relInsideRoot returns targetAbs relative to rootAbs, failing when the
+// target escapes the root. raw is the user-facing spelling for errors.
+func relInsideRoot(rootAbs string, targetAbs string, raw string) (string, error) {
+	rel, err := filepath.Rel(rootAbs, targetAbs)
+	if err != nil {
+		return "", fmt.Errorf("resolve %q relative to root: %w", raw, err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("file %q is outside root %s", raw, rootAbs)
+	}
+	return rel, nil
+}
+
 func symlinkPointsToDir(path string) (bool, error) {
 	stat, err := os.Stat(path)
 	if err != nil {
@@ -0,0 +1,82 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/procexec"
+)
+
+type fixturePhase string
+
+const (
+	fixtureBefore fixturePhase = "before"
+	fixtureAfter  fixturePhase = "after"
+)
+
+// fixtureOutputPath returns where shell fixture output for logAbs is captured.
+func fixtureOutputPath(logAbs string) string {
+	return strings.TrimSuffix(logAbs, ".log.md") + ".fixtures.txt"
+}
+
+// runFixtures runs shell fixture commands from the suite root and appends
+// their output to outputAbs. Before fixtures stop at the first failure; after
+// fixtures all run so cleanup is not cut short. The returned string describes
+// the first failing command and is empty when all succeeded.
+func runFixtures(
+	ctx context.Context,
+	phase fixturePhase,
+	commands []string,
+	rootAbs string,
+	outputAbs string,
+	deps Dependencies,
+) (string, error) {
+	if len(commands) == 0 {
+		return "", nil
+	}
+
+	output, err := os.OpenFile(outputAbs, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("open fixture output: %w", err)
+	}
+	defer func() {
+		_ = output.Close()
+	}()
+
+	failure := ""
+	for _, command := range commands {
+		_, _ = fmt.Fprintf(output, "$ %s  # %s\n", command, phase)
+		result, err := deps.Shell(ctx, ShellRequest{
+			RootAbs: rootAbs,
+			Command: command,
+			Output:  output,
+		})
+		if err != nil {
+			return "", fmt.Errorf("%s fixture %q: %w", phase, command, err)
+		}
+		_, _ = fmt.Fprintf(output, "[exit %d]\n", result.ExitCode)
+		if result.ExitCode == 0 {
+			continue
+		}
+		if failure == "" {
+			failure = fmt.Sprintf("%s fixture %q exited with code %d (output: %s)", phase, command, result.ExitCode, outputAbs)
+		}
+		if phase == fixtureBefore {
+			break
+		}
+	}
+	return failure, nil
+}
+
+// ProcessShell runs a shell fixture with sh -c in the suite root. It is the
+// default Dependencies.Shell.
+func ProcessShell(ctx context.Context, req ShellRequest) (ExecResult, error) {
+	result, err := procexec.RunShell(ctx, procexec.ShellRequest{
+		Dir:     req.RootAbs,
+		Command: req.Command,
+		Output:  req.Output,
+	})
+	return ExecResult{ExitCode: result.ExitCode}, err
+}
@@ -0,0 +1,137 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunExecutesFixturesAroundAgent(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "mdtest.yaml"), "before: root-up\nafter: root-down\n")
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "---\nbefore: test-up\nafter: test-down\n---\n# A\n")
+
+	var calls []string
+	deps := fixtureTestDeps(&calls, nil)
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Passed != 1 {
+		t.Fatalf("SuiteResult = %#v, want 1 passed", result)
+	}
+
+	want := []string{"sh:root-up", "sh:test-up", "agent", "sh:test-down", "sh:root-down"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %#v, want %#v", calls, want)
+	}
+
+	output, err := os.ReadFile(filepath.Join(root, "a.logs", "a.fixtures.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile fixture output: %v", err)
+	}
+	if !strings.Contains(string(output), "ran root-up") || !strings.Contains(string(output), "[exit 0]") {
+		t.Fatalf("fixture output = %q, want captured command output", output)
+	}
+}
+
+func TestRunReportsFailedBeforeFixtureAsError(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "---\nbefore: [up, never]\nafter: down\n---\n")
+
+	var calls []string
+	deps := fixtureTestDeps(&calls, map[string]int{"up": 4})
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Errored != 1 || result.Failed != 0 {
+		t.Fatalf("SuiteResult = %#v, want 1 error and no failures", result)
+	}
+	got := result.Results[0]
+	if got.Status != TestError || !strings.Contains(got.Reason, `before fixture "up" exited with code 4`) {
+		t.Fatalf("result = %#v, want before fixture error", got)
+	}
+
+	want := []string{"sh:up", "sh:down"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %#v, want %#v", calls, want)
+	}
+}
+
+func TestRunReportsFailedAfterFixtureAsError(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "---\nafter: [down, rm]\n---\n")
+
+	var calls []string
+	deps := fixtureTestDeps(&calls, map[string]int{"down": 1})
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Results[0].Status != TestError {
+		t.Fatalf("result = %#v, want error", result.Results[0])
+	}
+	want := []string{"agent", "sh:down", "sh:rm"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %#v, want %#v", calls, want)
+	}
+}
+
+func fixtureTestDeps(calls *[]string, exitCodes map[string]int) Dependencies {
+	return Dependencies{
+		DiscoverTests: DiscoverTests,
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			base := strings.TrimSuffix(filepath.Base(testAbs), ".test.md")
+			logDir := filepath.Join(filepath.Dir(testAbs), base+".logs")
+			return logDir, filepath.Join(logDir, base+".log.md"), nil
+		},
+		ParseLog:    func(string) (logs.Log, error) { return logs.Log{Status: logs.StatusPass}, nil },
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         time.Now,
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			*calls = append(*calls, "agent")
+			return ExecResult{}, nil
+		},
+		Shell: func(_ context.Context, req ShellRequest) (ExecResult, error) {
+			*calls = append(*calls, "sh:"+req.Command)
+			_, _ = fmt.Fprintf(req.Output, "ran %s\n", req.Command)
+			return ExecResult{ExitCode: exitCodes[req.Command]}, nil
+		},
+		Out: io.Discard,
+	}
+}
+
+func TestDefaultDependenciesRunFixturesInShell(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "---\nbefore: echo up > marker.txt\n---\n# A\n")
+
+	exec := func(_ context.Context, req ExecRequest) (ExecResult, error) {
+		return ExecResult{}, os.WriteFile(req.LogAbs, []byte("---\nstatus: pass\n---\n\nok\n"), 0o644)
+	}
+	suite, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, DefaultDependencies(io.Discard, exec))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if suite.Results[0].Status != TestPass {
+		t.Fatalf("result = %q %q, want pass", suite.Results[0].Status, suite.Results[0].Reason)
+	}
+	marker, err := os.ReadFile(filepath.Join(root, "marker.txt"))
+	if err != nil || string(marker) != "up\n" {
+		t.Fatalf("marker = %q, %v, want the fixture to have run in the suite root", marker, err)
+	}
+}
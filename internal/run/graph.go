@@ -0,0 +1,190 @@
+package run
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// resolveDependencies validates depends-on-tests for every planned case and
+// adds prerequisites that exist on disk but were not selected. It returns
+// the full, lexically sorted test list.
+func resolveDependencies(rootAbs string, cases map[string]TestCase, deps Dependencies) ([]string, error) {
+	pending := make([]string, 0, len(cases))
+	for testRel := range cases {
+		pending = append(pending, testRel)
+	}
+	sort.Strings(pending)
+
+	for len(pending) > 0 {
+		testRel := pending[0]
+		pending = pending[1:]
+
+		tc := cases[testRel]
+		tc.DependsOn = make([]string, 0, len(tc.File.Meta.DependsOnTests))
+		for _, raw := range tc.File.Meta.DependsOnTests {
+			if filepath.IsAbs(raw) {
+				return nil, fmt.Errorf("%s depends on %q: path must be relative to the suite root", testRel, raw)
+			}
+			depRel, err := resolveTestPath(rootAbs, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s depends on missing test: %w", testRel, err)
+			}
+			if depRel == testRel {
+				return nil, fmt.Errorf("%s depends on itself", testRel)
+			}
+			tc.DependsOn = append(tc.DependsOn, depRel)
+
+			if _, ok := cases[depRel]; ok {
+				continue
+			}
+			depCase, err := planTest(rootAbs, depRel, deps)
+			if err != nil {
+				return nil, err
+			}
+			cases[depRel] = depCase
+			pending = append(pending, depRel)
+		}
+		cases[testRel] = tc
+	}
+
+	tests := make([]string, 0, len(cases))
+	for testRel := range cases {
+		tests = append(tests, testRel)
+	}
+	sort.Strings(tests)
+	return tests, nil
+}
+
+// scheduleTests orders tests topologically. Among tests whose prerequisites
+// are all scheduled, the one earliest in ordered runs first, so the
+// requested order is kept wherever dependencies allow it.
+func scheduleTests(ordered []string, cases map[string]TestCase) ([]string, error) {
+	position := make(map[string]int, len(ordered))
+	for i, testRel := range ordered {
+		position[testRel] = i
+	}
+
+	waiting := make(map[string]int, len(ordered))
+	dependents := make(map[string][]string, len(ordered))
+	for _, testRel := range ordered {
+		for _, depRel := range cases[testRel].DependsOn {
+			waiting[testRel]++
+			dependents[depRel] = append(dependents[depRel], testRel)
+		}
+	}
+
+	ready := make([]string, 0, len(ordered))
+	for _, testRel := range ordered {
+		if waiting[testRel] == 0 {
+			ready = append(ready, testRel)
+		}
+	}
+
+	scheduled := make([]string, 0, len(ordered))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return position[ready[i]] < position[ready[j]] })
+		next := ready[0]
+		ready = ready[1:]
+		scheduled = append(scheduled, next)
+		for _, dependent := range dependents[next] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(scheduled) < len(ordered) {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(findCycle(ordered, cases, waiting), " -> "))
+	}
+	return scheduled, nil
+}
+
+// findCycle returns one dependency cycle among tests that could not be
+// scheduled, starting and ending with the same test.
+func findCycle(ordered []string, cases map[string]TestCase, waiting map[string]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(ordered))
+	var stack []string
+	var cycle []string
+
+	var visit func(testRel string) bool
+	visit = func(testRel string) bool {
+		state[testRel] = visiting
+		stack = append(stack, testRel)
+		for _, depRel := range cases[testRel].DependsOn {
+			switch state[depRel] {
+			case visiting:
+				for i, entry := range stack {
+					if entry == depRel {
+						cycle = append(append([]string(nil), stack[i:]...), depRel)
+						return true
+					}
+				}
+			case unvisited:
+				if visit(depRel) {
+					return true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[testRel] = done
+		return false
+	}
+
+	for _, testRel := range ordered {
+		if waiting[testRel] > 0 && state[testRel] == unvisited && visit(testRel) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// expandCases expands planned files into matrix cases keyed by case ID.
+// IDs keep the file order of tests and the matrix order within a file, and
+// file-level dependencies become dependencies on every case of that file.
+func expandCases(tests []string, planned map[string]TestCase) ([]string, map[string]TestCase) {
+	fileCases := make(map[string][]string, len(tests))
+	cases := make(map[string]TestCase, len(tests))
+	ids := make([]string, 0, len(tests))
+	for _, testRel := range tests {
+		tc := planned[testRel]
+		for _, params := range tc.File.Meta.Matrix.Expand() {
+			expanded := tc
+			expanded.ID = testfile.CaseID(testRel, params)
+			expanded.Params = params
+			cases[expanded.ID] = expanded
+			fileCases[testRel] = append(fileCases[testRel], expanded.ID)
+			ids = append(ids, expanded.ID)
+		}
+	}
+
+	for id, tc := range cases {
+		dependsOn := make([]string, 0, len(tc.DependsOn))
+		for _, depRel := range tc.DependsOn {
+			dependsOn = append(dependsOn, fileCases[depRel]...)
+		}
+		tc.DependsOn = dependsOn
+		cases[id] = tc
+	}
+	return ids, cases
+}
+
+// unmetDependency returns a skip reason when a prerequisite of tc did not pass.
+func unmetDependency(tc TestCase, results map[string]TestResult) string {
+	for _, depRel := range tc.DependsOn {
+		result := results[depRel]
+		if result.Status != TestPass {
+			return fmt.Sprintf("prerequisite %s did not pass (status=%s)", depRel, result.Status)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestScheduleTestsKeepsOrderWhereDependenciesAllow(t *testing.T) {
+	cases := map[string]TestCase{
+		"a.test.md":           {DependsOn: []string{"auth/signup.test.md"}},
+		"auth/signup.test.md": {},
+		"b.test.md":           {},
+	}
+
+	got, err := scheduleTests([]string{"a.test.md", "auth/signup.test.md", "b.test.md"}, cases)
+	if err != nil {
+		t.Fatalf("scheduleTests returned error: %v", err)
+	}
+	want := []string{"auth/signup.test.md", "a.test.md", "b.test.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("scheduleTests = %#v, want %#v", got, want)
+	}
+}
+
+func TestScheduleTestsReportsCycle(t *testing.T) {
+	cases := map[string]TestCase{
+		"a.test.md": {DependsOn: []string{"b.test.md"}},
+		"b.test.md": {DependsOn: []string{"c.test.md"}},
+		"c.test.md": {DependsOn: []string{"a.test.md"}},
+		"d.test.md": {},
+	}
+
+	_, err := scheduleTests([]string{"a.test.md", "b.test.md", "c.test.md", "d.test.md"}, cases)
+	if err == nil {
+		t.Fatal("scheduleTests returned nil error, want cycle")
+	}
+	if !strings.Contains(err.Error(), "a.test.md -> b.test.md -> c.test.md -> a.test.md") {
+		t.Fatalf("error = %q, want cycle path", err)
+	}
+}
+
+func TestRunSkipsDependentsOfFailedPrerequisite(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "auth", "signup.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "checkout.test.md"), "---\ndepends-on-tests: [auth/signup.test.md]\n---\n")
+	mustWriteFile(t, filepath.Join(root, "receipt.test.md"), "---\ndepends-on-tests: [checkout.test.md]\n---\n")
+
+	var executed []string
+	deps := graphTestDeps(root, &executed, "auth/signup.test.md")
+
+	result, err := Run(context.Background(), Config{
+		Root:  root,
+		Files: []string{"receipt.test.md"},
+		Agent: agent.ClaudeAgent,
+	}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(executed, []string{"auth/signup.test.md"}) {
+		t.Fatalf("executed = %#v, want only the prerequisite", executed)
+	}
+	if result.Total != 3 || result.Failed != 1 || result.Skipped != 2 {
+		t.Fatalf("SuiteResult = %#v, want 3 total, 1 failed, 2 skipped", result)
+	}
+	if got := result.Results[1]; got.TestRel != "checkout.test.md" || !strings.Contains(got.Reason, "prerequisite auth/signup.test.md") {
+		t.Fatalf("second result = %#v, want checkout skipped on signup", got)
+	}
+}
+
+func TestRunRejectsBadDependenciesBeforeAgentRuns(t *testing.T) {
+	tests := map[string]map[string]string{
+		"missing": {
+			"a.test.md": "---\ndepends-on-tests: [nope.test.md]\n---\n",
+		},
+		"outside root": {
+			"a.test.md": "---\ndepends-on-tests: [../a.test.md]\n---\n",
+		},
+		"cycle": {
+			"a.test.md": "---\ndepends-on-tests: [b.test.md]\n---\n",
+			"b.test.md": "---\ndepends-on-tests: [a.test.md]\n---\n",
+		},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			for rel, content := range files {
+				mustWriteFile(t, filepath.Join(root, rel), content)
+			}
+			var executed []string
+			_, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, graphTestDeps(root, &executed, ""))
+			if err == nil {
+				t.Fatal("Run returned nil error, want setup error")
+			}
+			if len(executed) != 0 {
+				t.Fatalf("executed = %#v, want no agent runs", executed)
+			}
+		})
+	}
+}
+
+func graphTestDeps(root string, executed *[]string, failing string) Dependencies {
+	return Dependencies{
+		DiscoverTests: DiscoverTests,
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			return filepath.Dir(testAbs), testAbs + ".log", nil
+		},
+		ParseLog: func(logAbs string) (logs.Log, error) {
+			if failing != "" && logAbs == filepath.Join(root, filepath.FromSlash(failing))+".log" {
+				return logs.Log{Status: logs.StatusFail}, nil
+			}
+			return logs.Log{Status: logs.StatusPass}, nil
+		},
+		BuildPrompt: func(in prompt.Input) (string, error) { return in.TestAbs, nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         time.Now,
+		Exec: func(_ context.Context, req ExecRequest) (ExecResult, error) {
+			rel, _ := filepath.Rel(root, req.Argv[len(req.Argv)-1])
+			*executed = append(*executed, filepath.ToSlash(rel))
+			return ExecResult{}, nil
+		},
+		Out: io.Discard,
+	}
+}
@@ -0,0 +1,175 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+const (
+	SetupHookName    = "_setup.md"
+	TeardownHookName = "_teardown.md"
+)
+
+type HookKind string
+
+const (
+	HookSetup    HookKind = "setup"
+	HookTeardown HookKind = "teardown"
+)
+
+type HookResult struct {
+	HookRel string
+	Kind    HookKind
+	LogAbs  string
+	Status  TestStatus
+	Reason  string
+}
+
+// hookRunner runs _setup.md before the first test under a directory and
+// _teardown.md after the last one. Hooks apply to every test below their
+// directory, outermost setup first and innermost teardown first.
+type hookRunner struct {
+	rootAbs   string
+	invoke    func(ctx context.Context, fileRel string) (agentOutcome, error)
+	remaining map[string]int
+	entered   []string
+	active    map[string]bool
+	failed    map[string]string
+	results   []HookResult
+}
+
+func newHookRunner(
+	rootAbs string,
+	tests []string,
+	invoke func(ctx context.Context, fileRel string) (agentOutcome, error),
+) *hookRunner {
+	remaining := make(map[string]int)
+	for _, testRel := range tests {
+		for _, dir := range ancestorDirs(testRel) {
+			remaining[dir]++
+		}
+	}
+	return &hookRunner{
+		rootAbs:   rootAbs,
+		invoke:    invoke,
+		remaining: remaining,
+		active:    make(map[string]bool),
+		failed:    make(map[string]string),
+	}
+}
+
+// enter runs pending setups for testRel. It returns a skip reason when a
+// setup for one of the test's directories failed.
+func (h *hookRunner) enter(ctx context.Context, testRel string) (string, error) {
+	for _, dir := range ancestorDirs(testRel) {
+		if reason := h.failed[dir]; reason != "" {
+			return reason, nil
+		}
+		if h.active[dir] {
+			continue
+		}
+		h.active[dir] = true
+		h.entered = append(h.entered, dir)
+
+		hookRel := path.Join(dir, SetupHookName)
+		if !h.exists(hookRel) {
+			continue
+		}
+		result, err := h.run(ctx, hookRel, HookSetup)
+		if err != nil {
+			return "", err
+		}
+		if result.Status != TestPass {
+			h.failed[dir] = fmt.Sprintf("setup %s failed: %s", hookRel, result.Reason)
+			return h.failed[dir], nil
+		}
+	}
+	return "", nil
+}
+
+// leave marks testRel as done and tears down directories with no tests left.
+func (h *hookRunner) leave(ctx context.Context, testRel string) error {
+	for _, dir := range ancestorDirs(testRel) {
+		h.remaining[dir]--
+	}
+	for i := len(h.entered) - 1; i >= 0; i-- {
+		dir := h.entered[i]
+		if h.remaining[dir] > 0 {
+			continue
+		}
+		h.entered = append(h.entered[:i], h.entered[i+1:]...)
+		if err := h.teardown(ctx, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish tears down every directory still entered, innermost first. It is
+// safe to call more than once and runs even when ctx is canceled.
+func (h *hookRunner) finish(ctx context.Context) error {
+	var firstErr error
+	for len(h.entered) > 0 {
+		last := len(h.entered) - 1
+		dir := h.entered[last]
+		h.entered = h.entered[:last]
+		if err := h.teardown(ctx, dir); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h *hookRunner) teardown(ctx context.Context, dir string) error {
+	hookRel := path.Join(dir, TeardownHookName)
+	if !h.exists(hookRel) {
+		return nil
+	}
+	_, err := h.run(context.WithoutCancel(ctx), hookRel, HookTeardown)
+	return err
+}
+
+func (h *hookRunner) run(ctx context.Context, hookRel string, kind HookKind) (HookResult, error) {
+	outcome, err := h.invoke(ctx, hookRel)
+	if err != nil {
+		return HookResult{}, fmt.Errorf("%s %s: %w", kind, hookRel, err)
+	}
+	result := HookResult{
+		HookRel: hookRel,
+		Kind:    kind,
+		LogAbs:  outcome.LogAbs,
+		Status:  TestPass,
+	}
+	if !outcome.passed() {
+		result.Status = TestFail
+		result.Reason = outcome.reason()
+	}
+	h.results = append(h.results, result)
+	return result, nil
+}
+
+func (h *hookRunner) exists(fileRel string) bool {
+	info, err := os.Stat(filepath.Join(h.rootAbs, filepath.FromSlash(fileRel)))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// ancestorDirs returns the suite-relative directories containing testRel,
+// from the root (".") down to the test's own directory.
+func ancestorDirs(testRel string) []string {
+	dir := path.Dir(testRel)
+	if dir == "." {
+		return []string{"."}
+	}
+	dirs := []string{dir}
+	for dir = path.Dir(dir); dir != "."; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, ".")
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	return dirs
+}
@@ -0,0 +1,168 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/config"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestAncestorDirs(t *testing.T) {
+	tests := map[string][]string{
+		"a.test.md":          {"."},
+		"x/a.test.md":        {".", "x"},
+		"x/y/z/deep.test.md": {".", "x", "x/y", "x/y/z"},
+	}
+	for testRel, want := range tests {
+		if got := ancestorDirs(testRel); !reflect.DeepEqual(got, want) {
+			t.Fatalf("ancestorDirs(%q) = %#v, want %#v", testRel, got, want)
+		}
+	}
+}
+
+func TestRunExecutesHooksAroundDirectories(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, SetupHookName), "")
+	mustWriteFile(t, filepath.Join(root, TeardownHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a", SetupHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a", TeardownHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a", "one.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "a", "two.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "")
+
+	var executed []string
+	deps := hookTestDeps(root, &executed, nil)
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	want := []string{
+		"_setup.md",
+		"a/_setup.md",
+		"a/one.test.md",
+		"a/two.test.md",
+		"a/_teardown.md",
+		"b.test.md",
+		"_teardown.md",
+	}
+	if !reflect.DeepEqual(executed, want) {
+		t.Fatalf("executed = %#v, want %#v", executed, want)
+	}
+	if result.Passed != 3 || len(result.Hooks) != 4 || result.HooksFailed != 0 {
+		t.Fatalf("SuiteResult = %#v, want 3 passed and 4 passing hooks", result)
+	}
+}
+
+func TestRunSkipsTestsWhenSetupFailsAndStillTearsDown(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a", SetupHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a", TeardownHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a", "one.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "")
+
+	var executed []string
+	deps := hookTestDeps(root, &executed, map[string]bool{"a/_setup.md": true})
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	want := []string{"a/_setup.md", "a/_teardown.md", "b.test.md"}
+	if !reflect.DeepEqual(executed, want) {
+		t.Fatalf("executed = %#v, want %#v", executed, want)
+	}
+	if result.Skipped != 1 || result.Passed != 1 || result.HooksFailed != 1 {
+		t.Fatalf("SuiteResult = %#v, want 1 skipped, 1 passed, 1 failed hook", result)
+	}
+	if result.Results[0].Status != TestSkip || !strings.Contains(result.Results[0].Reason, "a/_setup.md") {
+		t.Fatalf("first result = %#v, want skip naming the setup", result.Results[0])
+	}
+}
+
+func TestRunTearsDownWhenExecutionAborts(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, TeardownHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+
+	var executed []string
+	deps := hookTestDeps(root, &executed, nil)
+	exec := deps.Exec
+	deps.Exec = func(ctx context.Context, req ExecRequest) (ExecResult, error) {
+		if strings.Contains(req.Argv[len(req.Argv)-1], "a.test.md") {
+			return ExecResult{}, errors.New("interrupted")
+		}
+		return exec(ctx, req)
+	}
+
+	if _, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps); err == nil {
+		t.Fatal("Run returned nil error, want setup error")
+	}
+	if !reflect.DeepEqual(executed, []string{"_teardown.md"}) {
+		t.Fatalf("executed = %#v, want teardown after abort", executed)
+	}
+}
+
+func TestRunRendersHooksWithConfiguredTemplate(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, SetupHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+
+	var executed, rendered []string
+	deps := hookTestDeps(root, &executed, nil)
+	deps.LoadConfig = func(string, string) (config.Stack, error) {
+		return config.Stack{{Path: ".", Prompt: "prompt.tmpl"}}, nil
+	}
+	deps.BuildPrompt = func(prompt.Input) (string, error) {
+		return "", errors.New("built-in prompt should not be used")
+	}
+	deps.RenderPromptFile = func(path string, in prompt.Input) (string, error) {
+		if path != filepath.Join(root, "prompt.tmpl") {
+			t.Fatalf("template path = %q, want prompt.tmpl in root", path)
+		}
+		rendered = append(rendered, in.TestID)
+		return in.TestAbs, nil
+	}
+
+	if _, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if want := []string{"_setup.md", "a.test.md"}; !reflect.DeepEqual(rendered, want) {
+		t.Fatalf("rendered = %#v, want %#v", rendered, want)
+	}
+}
+
+func hookTestDeps(root string, executed *[]string, failing map[string]bool) Dependencies {
+	return Dependencies{
+		DiscoverTests: DiscoverTests,
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			return filepath.Dir(testAbs), testAbs + ".log", nil
+		},
+		ParseLog: func(logAbs string) (logs.Log, error) {
+			rel, _ := filepath.Rel(root, strings.TrimSuffix(logAbs, ".log"))
+			if failing[filepath.ToSlash(rel)] {
+				return logs.Log{Status: logs.StatusFail}, nil
+			}
+			return logs.Log{Status: logs.StatusPass}, nil
+		},
+		BuildPrompt: func(in prompt.Input) (string, error) { return in.TestAbs, nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         time.Now,
+		Exec: func(_ context.Context, req ExecRequest) (ExecResult, error) {
+			rel, _ := filepath.Rel(root, req.Argv[len(req.Argv)-1])
+			*executed = append(*executed, filepath.ToSlash(rel))
+			return ExecResult{}, nil
+		},
+	}
+}
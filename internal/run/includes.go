@@ -0,0 +1,98 @@
+package run
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+var includePattern = regexp.MustCompile(`^\s*<!--\s*include:\s*(.+?)\s*-->\s*$`)
+
+// expandIncludes replaces <!-- include: path --> lines in body with the body
+// of the referenced Markdown file. Paths are relative to the including file
+// and must stay inside rootAbs. Directives inside fenced code blocks are left
+// alone. Included front matter is dropped. bodyOffset is the number of
+// front matter lines before body, so errors give the line in the file.
+func expandIncludes(rootAbs string, fileAbs string, body string, bodyOffset int) (string, error) {
+	return expandIncludesFrom(rootAbs, fileAbs, body, bodyOffset, []string{fileAbs})
+}
+
+func expandIncludesFrom(rootAbs string, fileAbs string, body string, bodyOffset int, stack []string) (string, error) {
+	fileRel, err := filepath.Rel(rootAbs, fileAbs)
+	if err != nil {
+		fileRel = fileAbs
+	}
+	fileRel = filepath.ToSlash(fileRel)
+
+	lines := strings.SplitAfter(body, "\n")
+	var b strings.Builder
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		match := includePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if inFence || match == nil {
+			b.WriteString(line)
+			continue
+		}
+
+		raw := match[1]
+		where := fmt.Sprintf("%s:%d", fileRel, bodyOffset+i+1)
+		if filepath.IsAbs(raw) {
+			return "", fmt.Errorf("%s: include %q must be a relative path", where, raw)
+		}
+		targetAbs := filepath.Clean(filepath.Join(filepath.Dir(fileAbs), filepath.FromSlash(raw)))
+		if _, err := relInsideRoot(rootAbs, targetAbs, raw); err != nil {
+			return "", fmt.Errorf("%s: include: %w", where, err)
+		}
+		for _, seen := range stack {
+			if seen == targetAbs {
+				return "", fmt.Errorf("%s: include cycle: %s", where, includeChain(rootAbs, append(stack, targetAbs)))
+			}
+		}
+
+		content, err := os.ReadFile(targetAbs)
+		if err != nil {
+			return "", fmt.Errorf("%s: include %q: %w", where, raw, err)
+		}
+		included, err := testfile.Parse(content)
+		if err != nil {
+			return "", fmt.Errorf("%s: include %q: %w", where, raw, err)
+		}
+		expanded, err := expandIncludesFrom(rootAbs, targetAbs, included.Body, included.BodyOffset, append(stack, targetAbs))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(expanded)
+		if !strings.HasSuffix(expanded, "\n") && strings.HasSuffix(line, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
+
+func includeChain(rootAbs string, stack []string) string {
+	parts := make([]string, 0, len(stack))
+	for _, fileAbs := range stack {
+		rel, err := filepath.Rel(rootAbs, fileAbs)
+		if err != nil {
+			rel = fileAbs
+		}
+		parts = append(parts, filepath.ToSlash(rel))
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// contentHash identifies the exact test body handed to the agent.
+func contentHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
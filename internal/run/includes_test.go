@@ -0,0 +1,103 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+func TestExpandIncludesNestedRelativeToIncludingFile(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "shared", "login.md"), "---\ntitle: ignored\n---\n1. Open ${BASE_URL}.\n<!-- include: creds.md -->\n")
+	mustWriteFile(t, filepath.Join(root, "shared", "creds.md"), "2. Sign in as admin.")
+	testAbs := filepath.Join(root, "flows", "checkout.test.md")
+
+	body := "# Checkout\n<!-- include: ../shared/login.md -->\n3. Buy.\n```\n<!-- include: ../shared/nope.md -->\n```\n"
+	got, err := expandIncludes(root, testAbs, body, 0)
+	if err != nil {
+		t.Fatalf("expandIncludes returned error: %v", err)
+	}
+
+	want := "# Checkout\n1. Open ${BASE_URL}.\n2. Sign in as admin.\n3. Buy.\n```\n<!-- include: ../shared/nope.md -->\n```\n"
+	if got != want {
+		t.Fatalf("expandIncludes = %q, want %q", got, want)
+	}
+}
+
+func TestExpandIncludesFailures(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.md"), "<!-- include: b.md -->\n")
+	mustWriteFile(t, filepath.Join(root, "b.md"), "<!-- include: a.md -->\n")
+	testAbs := filepath.Join(root, "x.test.md")
+
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"cycle":        {body: "<!-- include: a.md -->\n", want: "include cycle: x.test.md -> a.md -> b.md -> a.md"},
+		"outside root": {body: "<!-- include: ../secret.md -->\n", want: "outside root"},
+		"missing":      {body: "ok\n<!-- include: nope.md -->\n", want: "x.test.md:2"},
+		"absolute":     {body: "<!-- include: /etc/passwd -->\n", want: "relative path"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := expandIncludes(root, testAbs, tt.body, 0)
+			if err == nil {
+				t.Fatal("expandIncludes returned nil error, want failure")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandIncludesCountsFrontMatterLines(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "shared", "login.md"), "---\ntitle: login\n---\n1. Open.\n<!-- include: nope.md -->\n")
+	testAbs := filepath.Join(root, "x.test.md")
+	mustWriteFile(t, testAbs, "---\nrequires: [browser]\nside-effects: true\n---\n# X\n<!-- include: shared/login.md -->\n")
+
+	file, err := testfile.Read(testAbs)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	_, err = expandIncludes(root, testAbs, file.Body, file.BodyOffset)
+	if err == nil || !strings.Contains(err.Error(), "shared/login.md:5:") {
+		t.Fatalf("error = %v, want the include's line in shared/login.md", err)
+	}
+
+	_, err = expandIncludes(root, testAbs, "<!-- include: nope.md -->\n", file.BodyOffset)
+	if err == nil || !strings.Contains(err.Error(), "x.test.md:5:") {
+		t.Fatalf("error = %v, want line 5 of x.test.md", err)
+	}
+}
+
+func TestRunHandsExpandedTestToAgentAndRecordsHash(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "shared", "login.md"), "Log in.\n")
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "<!-- include: shared/login.md -->\nCheck out.\n")
+
+	var inputs []prompt.Input
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, varsTestDeps(&inputs))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	rendered, err := os.ReadFile(inputs[0].TestAbs)
+	if err != nil {
+		t.Fatalf("ReadFile rendered test: %v", err)
+	}
+	if string(rendered) != "Log in.\nCheck out.\n" {
+		t.Fatalf("rendered test = %q, want expanded include", rendered)
+	}
+	if got := result.Results[0].ContentHash; got != contentHash("Log in.\nCheck out.\n") {
+		t.Fatalf("ContentHash = %q, want hash of expanded body", got)
+	}
+}
@@ -0,0 +1,135 @@
+package run
+
+import (
+	"context"
+	"fmt"
+)
+
+// runCases runs cases, which are in schedule order, up to cfg.Jobs at a
+// time. A case starts once every case it depends on has finished, earliest
+// in the schedule first, so with one job the schedule is followed exactly.
+// Skips, hooks and record all happen on the calling goroutine; record is
+// called as each case finishes. After the first error no more cases start,
+// running ones are canceled, and the error is returned once they return.
+func runCases(
+	ctx context.Context,
+	cfg Config,
+	cases []TestCase,
+	hooks *hookRunner,
+	deps Dependencies,
+	record func(i int, result TestResult) error,
+) error {
+	jobs := max(cfg.Jobs, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		index  int
+		result TestResult
+		err    error
+	}
+	done := make(chan outcome)
+	finished := make(map[string]TestResult, len(cases))
+	started := make([]bool, len(cases))
+	running := 0
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	complete := func(i int, result TestResult) {
+		finished[cases[i].ID] = result
+		if err := record(i, result); err != nil {
+			fail(err)
+		}
+	}
+
+	for {
+		for firstErr == nil && running < jobs {
+			i := nextReady(cases, started, finished)
+			if i < 0 {
+				break
+			}
+			started[i] = true
+			tc := cases[i]
+
+			skipReason := markedSkip(cfg, tc)
+			if skipReason == "" {
+				skipReason = unmetDependency(tc, finished)
+			}
+			if skipReason == "" {
+				reason, err := hooks.enter(ctx, tc.TestRel)
+				if err != nil {
+					fail(err)
+					break
+				}
+				skipReason = reason
+			}
+			if skipReason != "" {
+				complete(i, TestResult{ID: tc.ID, TestRel: tc.TestRel, Status: TestSkip, Reason: skipReason})
+				continue
+			}
+
+			running++
+			go func() {
+				startedAt := deps.Now()
+				result, err := runTest(ctx, cfg, tc, deps)
+				if err == nil {
+					result.Elapsed = deps.Now().Sub(startedAt)
+					result = applyXFail(cfg, tc, result)
+				}
+				done <- outcome{index: i, result: result, err: err}
+			}()
+		}
+		if running == 0 {
+			return firstErr
+		}
+		o := <-done
+		running--
+		switch {
+		case o.err != nil:
+			fail(o.err)
+		case firstErr == nil:
+			complete(o.index, o.result)
+		}
+	}
+}
+
+// nextReady returns the first case not yet started whose prerequisites have
+// all finished, or -1 when none is ready.
+func nextReady(cases []TestCase, started []bool, finished map[string]TestResult) int {
+	for i, tc := range cases {
+		if started[i] {
+			continue
+		}
+		ready := true
+		for _, depID := range tc.DependsOn {
+			if _, ok := finished[depID]; !ok {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkJobs rejects settings that cannot work with tests running at once.
+func checkJobs(cfg Config) error {
+	if cfg.Jobs <= 1 {
+		return nil
+	}
+	if cfg.Interactive {
+		return fmt.Errorf("interactive mode runs one test at a time; use 1 job")
+	}
+	if cfg.RepairLimit > 0 {
+		// A repair resumes the latest agent session in the suite root, which
+		// is ambiguous while several agents run there.
+		return fmt.Errorf("log repairs resume the latest agent session, so they need 1 job")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package run
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+)
+
+func TestRunWithJobsOverlapsIndependentTests(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "c.test.md"), "---\ndepends-on-tests: [a.test.md]\n---\n")
+
+	var executed []string
+	deps := graphTestDeps(root, &executed, "")
+	var (
+		mu       sync.Mutex
+		running  = map[string]bool{}
+		finished = map[string]bool{}
+		events   []string
+	)
+	bothStarted := make(chan struct{})
+	deps.Exec = func(_ context.Context, req ExecRequest) (ExecResult, error) {
+		mu.Lock()
+		running[req.ID] = true
+		events = append(events, "start "+req.ID)
+		if running["a.test.md"] && running["b.test.md"] && req.ID != "c.test.md" {
+			close(bothStarted)
+		}
+		if req.ID == "c.test.md" && !finished["a.test.md"] {
+			t.Errorf("c.test.md started before its prerequisite a.test.md finished")
+		}
+		mu.Unlock()
+
+		if req.ID != "c.test.md" {
+			select {
+			case <-bothStarted:
+			case <-time.After(5 * time.Second):
+				t.Errorf("%s: independent tests did not run at once", req.ID)
+			}
+		}
+
+		mu.Lock()
+		delete(running, req.ID)
+		finished[req.ID] = true
+		mu.Unlock()
+		return ExecResult{}, nil
+	}
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, Jobs: 2}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Passed != 3 {
+		t.Fatalf("SuiteResult = %#v, want 3 passed", result)
+	}
+	var ids []string
+	for _, r := range result.Results {
+		ids = append(ids, r.ID)
+	}
+	if want := []string{"a.test.md", "b.test.md", "c.test.md"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("result order = %#v, want %#v", ids, want)
+	}
+}
+
+func TestRunRejectsJobsWithRepairsOrInteractive(t *testing.T) {
+	tests := map[string]Config{
+		"repairs":     {RepairLimit: 1},
+		"interactive": {Interactive: true},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+			cfg.Root = root
+			cfg.Agent = agent.ClaudeAgent
+			cfg.Jobs = 2
+
+			var executed []string
+			_, err := Run(context.Background(), cfg, graphTestDeps(root, &executed, ""))
+			if _, ok := err.(*SetupError); !ok {
+				t.Fatalf("Run error = %#v, want *SetupError", err)
+			}
+			if len(executed) != 0 {
+				t.Fatalf("executed = %#v, want no agent runs", executed)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package run
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// NewJSONReporter returns a reporter that writes every event to w as one
+// line of JSON with an "event" key, for tools that follow a run live.
+// Agent output is not included.
+func NewJSONReporter(w io.Writer) Reporter {
+	return &jsonReporter{enc: json.NewEncoder(w)}
+}
+
+type jsonReporter struct {
+	BaseReporter
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+type jsonEvent struct {
+	Event    string       `json:"event"`
+	ID       string       `json:"id,omitempty"`
+	Test     string       `json:"test,omitempty"`
+	Index    int          `json:"index,omitempty"`
+	Root     string       `json:"root,omitempty"`
+	Agent    string       `json:"agent,omitempty"`
+	Order    string       `json:"order,omitempty"`
+	Seed     uint64       `json:"seed,omitempty"`
+	IDs      []string     `json:"ids,omitempty"`
+	Attempt  int          `json:"attempt,omitempty"`
+	ExitCode *int         `json:"exit_code,omitempty"`
+	Log      string       `json:"log,omitempty"`
+	Status   string       `json:"status,omitempty"`
+	Reason   string       `json:"reason,omitempty"`
+	Error    string       `json:"error,omitempty"`
+	Elapsed  int64        `json:"elapsed_ms,omitempty"`
+	Cases    []jsonCase   `json:"cases,omitempty"`
+	Counts   *jsonCounts  `json:"counts,omitempty"`
+	Hooks    []jsonFailed `json:"failed_hooks,omitempty"`
+}
+
+type jsonCase struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type jsonCounts struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Errors  int `json:"errors"`
+	XFailed int `json:"xfailed"`
+	XPassed int `json:"xpassed"`
+}
+
+type jsonFailed struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+func (r *jsonReporter) emit(event jsonEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(event)
+}
+
+func (r *jsonReporter) SuiteStarted(start SuiteStart) {
+	r.emit(jsonEvent{
+		Event: "suite_started",
+		Root:  start.RootAbs,
+		Agent: string(start.Config.Agent),
+		Order: string(start.Order),
+		Seed:  start.Seed,
+		IDs:   start.IDs,
+	})
+}
+
+func (r *jsonReporter) TestScheduled(test ScheduledTest) {
+	r.emit(jsonEvent{Event: "test_scheduled", ID: test.ID, Test: test.TestRel, Index: test.Index})
+}
+
+func (r *jsonReporter) TestStarted(test TestStart) {
+	r.emit(jsonEvent{Event: "test_started", ID: test.ID, Test: test.TestRel, Log: test.LogAbs})
+}
+
+func (r *jsonReporter) AgentStarted(start AgentStart) {
+	r.emit(jsonEvent{Event: "agent_started", ID: start.ID, Agent: string(start.Agent), Attempt: start.Attempt})
+}
+
+func (r *jsonReporter) AgentExited(exit AgentExit) {
+	code := exit.ExitCode
+	r.emit(jsonEvent{Event: "agent_exited", ID: exit.ID, Attempt: exit.Attempt, ExitCode: &code})
+}
+
+func (r *jsonReporter) LogParsed(parsed LogParsed) {
+	event := jsonEvent{Event: "log_parsed", ID: parsed.ID, Log: parsed.LogAbs, Status: string(parsed.Log.Status)}
+	if parsed.Err != nil {
+		event.Error = describeLogError(parsed.Err)
+	}
+	r.emit(event)
+}
+
+func (r *jsonReporter) TestFinished(result TestResult) {
+	event := jsonEvent{
+		Event:   "test_finished",
+		ID:      result.ID,
+		Test:    result.TestRel,
+		Log:     result.LogAbs,
+		Status:  string(result.Status),
+		Reason:  result.Reason,
+		Elapsed: result.Elapsed.Milliseconds(),
+	}
+	for _, c := range result.Cases {
+		event.Cases = append(event.Cases, jsonCase{Name: c.Name, Status: string(c.Status), Reason: c.Reason})
+	}
+	r.emit(event)
+}
+
+func (r *jsonReporter) SuiteFinished(suite SuiteResult) {
+	event := jsonEvent{
+		Event:   "suite_finished",
+		Elapsed: suite.Elapsed.Milliseconds(),
+		Counts: &jsonCounts{
+			Total:   suite.Total,
+			Passed:  suite.Passed,
+			Failed:  suite.Failed,
+			Skipped: suite.Skipped,
+			Errors:  suite.Errored,
+			XFailed: suite.XFailed,
+			XPassed: suite.XPassed,
+		},
+	}
+	for _, hook := range suite.Hooks {
+		if hook.Status != TestPass {
+			event.Hooks = append(event.Hooks, jsonFailed{Path: hook.HookRel, Kind: string(hook.Kind), Reason: hook.Reason})
+		}
+	}
+	r.emit(event)
+}
+
+func (r *jsonReporter) SuiteAborted(err error) {
+	r.emit(jsonEvent{Event: "suite_aborted", Error: err.Error()})
+}
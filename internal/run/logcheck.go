@@ -0,0 +1,68 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+)
+
+// LogState says whether the agent left a usable log.
+type LogState string
+
+const (
+	LogOK      LogState = "ok"
+	LogMissing LogState = "missing"
+	LogInvalid LogState = "invalid"
+)
+
+// InvalidLogError lists the schema violations --strict-log found in a log.
+type InvalidLogError struct {
+	Diagnostics []logs.Diagnostic
+}
+
+func (e *InvalidLogError) Error() string {
+	parts := make([]string, 0, len(e.Diagnostics))
+	for _, diag := range e.Diagnostics {
+		parts = append(parts, diag.String())
+	}
+	return "schema: " + strings.Join(parts, "; ")
+}
+
+// readLog parses the log at logAbs and, with cfg.StrictLog, checks it
+// against the full log schema.
+func readLog(cfg Config, logAbs string, deps Dependencies) (logs.Log, error) {
+	log, err := deps.ParseLog(logAbs)
+	if err != nil || !cfg.StrictLog {
+		return log, err
+	}
+	diags, err := deps.ValidateLog(logAbs)
+	if err != nil {
+		return logs.Log{}, err
+	}
+	if len(diags) > 0 {
+		return logs.Log{}, &InvalidLogError{Diagnostics: diags}
+	}
+	return log, nil
+}
+
+func logState(err error) LogState {
+	switch {
+	case err == nil:
+		return LogOK
+	case errors.Is(err, fs.ErrNotExist):
+		return LogMissing
+	default:
+		return LogInvalid
+	}
+}
+
+// describeLogError explains why a log could not be used.
+func describeLogError(err error) string {
+	if logState(err) == LogMissing {
+		return "agent produced no log"
+	}
+	return fmt.Sprintf("invalid log: %v", err)
+}
@@ -0,0 +1,63 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunDistinguishesMissingAndInvalidLogs(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        string
+		strict     bool
+		wantStatus TestStatus
+		wantState  LogState
+		wantReason string
+	}{
+		{name: "missing", wantStatus: TestFail, wantState: LogMissing, wantReason: "agent produced no log (agent exit code 0)"},
+		{name: "invalid", log: "status: pass\n", wantStatus: TestFail, wantState: LogInvalid, wantReason: "invalid log: front matter must start at byte 0"},
+		{name: "lenient body", log: "---\nstatus: pass\n---\n", wantStatus: TestPass, wantState: LogOK},
+		{name: "strict body", log: "---\nstatus: pass\n---\n", strict: true, wantStatus: TestFail, wantState: LogInvalid, wantReason: "invalid log: schema: line 3: log body is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+			deps := Dependencies{
+				DiscoverTests: func(string) ([]string, error) { return []string{"a.test.md"}, nil },
+				NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+					logDir := filepath.Join(filepath.Dir(testAbs), "a.logs")
+					return logDir, filepath.Join(logDir, "a.log.md"), nil
+				},
+				BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+				MkdirAll:    os.MkdirAll,
+				Now:         time.Now,
+				Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+					if tt.log != "" {
+						mustWriteFile(t, filepath.Join(root, "a.logs", "a.log.md"), tt.log)
+					}
+					return ExecResult{}, nil
+				},
+				Out: io.Discard,
+			}
+
+			suite, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, StrictLog: tt.strict}, deps)
+			if err != nil {
+				t.Fatalf("Run returned error: %v", err)
+			}
+			got := suite.Results[0]
+			if got.Status != tt.wantStatus || got.LogState != tt.wantState || !strings.HasPrefix(got.Reason, tt.wantReason) {
+				t.Fatalf("result = %q/%q/%q, want %q/%q/%q...", got.Status, got.LogState, got.Reason, tt.wantStatus, tt.wantState, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunExpandsMatrixIntoCases(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "checkout.test.md"), "---\nmatrix:\n  role: [admin, viewer]\n  locale: ja\n---\n")
+	mustWriteFile(t, filepath.Join(root, "receipt.test.md"), "---\ndepends-on-tests: [checkout.test.md]\n---\n")
+
+	var inputs []prompt.Input
+	deps := Dependencies{
+		DiscoverTests: DiscoverTests,
+		NextLogPath:   logs.NextLogPath,
+		ParseLog: func(logAbs string) (logs.Log, error) {
+			if strings.Contains(logAbs, "role=viewer") {
+				return logs.Log{Status: logs.StatusFail}, nil
+			}
+			return logs.Log{Status: logs.StatusPass}, nil
+		},
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			inputs = append(inputs, in)
+			return "prompt", nil
+		},
+		MkdirAll: os.MkdirAll,
+		Now: func() time.Time {
+			return time.Date(2026, time.February, 11, 10, 0, 0, 0, time.UTC)
+		},
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			return ExecResult{}, nil
+		},
+		Out: io.Discard,
+	}
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	var ids []string
+	for _, r := range result.Results {
+		ids = append(ids, r.ID)
+	}
+	wantIDs := []string{
+		"checkout.test.md[role=admin,locale=ja]",
+		"checkout.test.md[role=viewer,locale=ja]",
+		"receipt.test.md",
+	}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Fatalf("result IDs = %#v, want %#v", ids, wantIDs)
+	}
+	if result.Total != 3 || result.Passed != 1 || result.Failed != 1 || result.Skipped != 1 {
+		t.Fatalf("SuiteResult = %#v, want 1 passed, 1 failed, 1 skipped", result)
+	}
+
+	wantLog := filepath.Join(root, "checkout[role=admin,locale=ja].logs", "2026-02-11T10-00-00Z.log.md")
+	if result.Results[0].LogAbs != wantLog {
+		t.Fatalf("LogAbs = %q, want %q", result.Results[0].LogAbs, wantLog)
+	}
+	if len(inputs) != 2 || inputs[0].TestAbs != filepath.Join(root, "checkout.test.md") {
+		t.Fatalf("prompt inputs = %#v, want two checkout cases", inputs)
+	}
+	if got := inputs[1].Params; len(got) != 2 || got[0].Value != "viewer" || got[1].Value != "ja" {
+		t.Fatalf("second case params = %#v, want role=viewer, locale=ja", got)
+	}
+}
+
+func TestRunKeepsLogsOfMatrixValuesWithSlashesNextToTheTest(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "checkout.test.md"), "---\nmatrix:\n  url: [http://a/b, ../../x]\n---\nOpen ${url}.\n")
+
+	deps := DefaultDependencies(io.Discard, func(_ context.Context, req ExecRequest) (ExecResult, error) {
+		return ExecResult{}, os.WriteFile(req.LogAbs, []byte("---\nstatus: pass\n---\n\nOK.\n"), 0o644)
+	})
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Passed != 2 {
+		t.Fatalf("SuiteResult = %#v, want 2 passed", result)
+	}
+	for _, r := range result.Results {
+		if dir := filepath.Dir(filepath.Dir(r.LogAbs)); dir != root {
+			t.Fatalf("log of %s = %q, want a log directory directly in the root", r.ID, r.LogAbs)
+		}
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{"checkout.test.md", "checkout[url=..%2F..%2Fx].logs", "checkout[url=http%3A%2F%2Fa%2Fb].logs"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("root holds %#v, want %#v", names, want)
+	}
+}
@@ -0,0 +1,86 @@
+package run
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+)
+
+type Order string
+
+const (
+	OrderLexical      Order = "lexical"
+	OrderRandom       Order = "random"
+	OrderSlowestFirst Order = "slowest-first"
+	OrderFailedFirst  Order = "failed-first"
+)
+
+type InvalidOrderError struct {
+	Raw string
+}
+
+func (e *InvalidOrderError) Error() string {
+	return fmt.Sprintf("invalid order %q (expected lexical, random, slowest-first, or failed-first)", e.Raw)
+}
+
+func ParseOrder(raw string) (Order, error) {
+	order := Order(strings.TrimSpace(strings.ToLower(raw)))
+	switch order {
+	case "":
+		return OrderLexical, nil
+	case OrderLexical, OrderRandom, OrderSlowestFirst, OrderFailedFirst:
+		return order, nil
+	default:
+		return "", &InvalidOrderError{Raw: raw}
+	}
+}
+
+// orderTests returns a reordered copy of tests, which must already be in
+// lexical order. latest looks up the previous run of a test ID.
+func orderTests(
+	tests []string,
+	order Order,
+	seed uint64,
+	latest func(id string) (logs.PreviousRun, bool, error),
+) ([]string, error) {
+	ordered := append([]string(nil), tests...)
+
+	switch order {
+	case "", OrderLexical:
+		return ordered, nil
+	case OrderRandom:
+		rng := rand.New(rand.NewPCG(seed, seed))
+		rng.Shuffle(len(ordered), func(i, j int) {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		})
+		return ordered, nil
+	case OrderSlowestFirst, OrderFailedFirst:
+		previous := make(map[string]logs.PreviousRun, len(ordered))
+		for _, id := range ordered {
+			prev, ok, err := latest(id)
+			if err != nil {
+				return nil, fmt.Errorf("read previous run of %s: %w", id, err)
+			}
+			if ok {
+				previous[id] = prev
+			}
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			a, aok := previous[ordered[i]]
+			b, bok := previous[ordered[j]]
+			if order == OrderFailedFirst {
+				return aok && a.Failed() && !(bok && b.Failed())
+			}
+			if !aok || !bok {
+				return aok && !bok
+			}
+			return a.Duration > b.Duration
+		})
+		return ordered, nil
+	default:
+		return nil, &InvalidOrderError{Raw: string(order)}
+	}
+}
@@ -0,0 +1,71 @@
+package run
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+)
+
+func TestParseOrder(t *testing.T) {
+	for _, raw := range []string{"lexical", "random", "slowest-first", " Failed-First ", ""} {
+		if _, err := ParseOrder(raw); err != nil {
+			t.Fatalf("ParseOrder(%q) returned error: %v", raw, err)
+		}
+	}
+	if _, err := ParseOrder("sideways"); err == nil {
+		t.Fatal("ParseOrder(sideways) returned nil error")
+	}
+}
+
+func TestOrderTestsRandomIsReproducibleForSeed(t *testing.T) {
+	tests := []string{"a.test.md", "b.test.md", "c.test.md", "d.test.md", "e.test.md", "f.test.md"}
+
+	first, err := orderTests(tests, OrderRandom, 7, nil)
+	if err != nil {
+		t.Fatalf("orderTests returned error: %v", err)
+	}
+	second, err := orderTests(tests, OrderRandom, 7, nil)
+	if err != nil {
+		t.Fatalf("orderTests returned error: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("same seed produced %#v and %#v", first, second)
+	}
+	if reflect.DeepEqual(first, tests) {
+		t.Fatalf("seed 7 left order unchanged: %#v", first)
+	}
+}
+
+func TestOrderTestsUsesPreviousRuns(t *testing.T) {
+	previous := map[string]logs.PreviousRun{
+		"a.test.md": {Status: logs.StatusPass, Duration: time.Second},
+		"b.test.md": {Status: logs.StatusFail, Duration: 3 * time.Second},
+		"c.test.md": {ParseErr: errors.New("bad log"), Duration: 2 * time.Second},
+	}
+	latest := func(id string) (logs.PreviousRun, bool, error) {
+		prev, ok := previous[id]
+		return prev, ok, nil
+	}
+	tests := []string{"a.test.md", "b.test.md", "c.test.md", "d.test.md"}
+
+	got, err := orderTests(tests, OrderFailedFirst, 0, latest)
+	if err != nil {
+		t.Fatalf("orderTests returned error: %v", err)
+	}
+	want := []string{"b.test.md", "c.test.md", "a.test.md", "d.test.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("failed-first = %#v, want %#v", got, want)
+	}
+
+	got, err = orderTests(tests, OrderSlowestFirst, 0, latest)
+	if err != nil {
+		t.Fatalf("orderTests returned error: %v", err)
+	}
+	want = []string{"b.test.md", "c.test.md", "a.test.md", "d.test.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("slowest-first = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/config"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// PromptMode selects how the agent receives the test.
+type PromptMode string
+
+const (
+	// PromptModePath tells the agent the absolute path to read the test from.
+	PromptModePath PromptMode = "path"
+	// PromptModeInline embeds the expanded test Markdown in the prompt.
+	PromptModeInline PromptMode = "inline"
+)
+
+type InvalidPromptModeError struct {
+	Raw string
+}
+
+func (e *InvalidPromptModeError) Error() string {
+	return fmt.Sprintf("invalid prompt mode %q (expected path or inline)", e.Raw)
+}
+
+func ParsePromptMode(raw string) (PromptMode, error) {
+	mode := PromptMode(strings.TrimSpace(strings.ToLower(raw)))
+	switch mode {
+	case "":
+		return PromptModePath, nil
+	case PromptModePath, PromptModeInline:
+		return mode, nil
+	default:
+		return "", &InvalidPromptModeError{Raw: raw}
+	}
+}
+
+type PromptPreview struct {
+	ID     string
+	Prompt string
+}
+
+// PreviewPrompts renders the prompts that Run would send for the tests
+// selected by cfg, without creating log directories or running anything.
+// Log paths are the ones a run started now would use.
+func PreviewPrompts(cfg Config, deps Dependencies) ([]PromptPreview, error) {
+	deps = fillDefaults(deps)
+
+	rootAbs, err := resolveRoot(cfg)
+	if err != nil {
+		return nil, &SetupError{Err: err}
+	}
+	tests, err := selectTests(rootAbs, cfg, deps)
+	if err != nil {
+		return nil, &SetupError{Err: err}
+	}
+	selected := make(map[string]bool, len(tests))
+	for _, testRel := range tests {
+		selected[testRel] = true
+	}
+	ids, cases, err := planCases(rootAbs, tests, cfg, deps)
+	if err != nil {
+		return nil, &SetupError{Err: err}
+	}
+
+	previews := make([]PromptPreview, 0, len(ids))
+	for _, id := range ids {
+		tc := cases[id]
+		if !selected[tc.TestRel] {
+			continue
+		}
+		_, logAbs, err := deps.NextLogPath(testfile.CaseFile(tc.TestAbs, tc.Params), deps.Now().UTC())
+		if err != nil {
+			return nil, &SetupError{Err: fmt.Errorf("next log path for %s: %w", id, err)}
+		}
+		inputAbs := tc.TestAbs
+		if tc.Body != tc.File.Body {
+			inputAbs = renderedTestPath(logAbs)
+		}
+		text, err := renderCasePrompt(cfg, tc, inputAbs, logAbs, 1, deps)
+		if err != nil {
+			return nil, &SetupError{Err: err}
+		}
+		previews = append(previews, PromptPreview{ID: id, Prompt: text})
+	}
+	return previews, nil
+}
+
+// renderCasePrompt renders the prompt for one agent invocation of tc, using
+// the mdtest.yaml template when one applies. In inline mode the expanded body
+// is embedded and inputAbs is not referenced.
+func renderCasePrompt(cfg Config, tc TestCase, inputAbs string, logAbs string, attempt int, deps Dependencies) (string, error) {
+	in := prompt.Input{
+		TestAbs:      inputAbs,
+		LogAbs:       logAbs,
+		TestID:       tc.ID,
+		FrontMatter:  tc.File.FrontMatter,
+		Params:       tc.Params,
+		Vars:         tc.Vars,
+		Attempt:      attempt,
+		Cases:        tc.CaseNames,
+		Capabilities: tc.File.Meta.Requires,
+		Context:      tc.Context,
+	}
+	if cfg.PromptMode == PromptModeInline {
+		in.TestAbs = tc.TestAbs
+		in.TestBody = strings.TrimRight(tc.Body, "\r\n")
+		in.ContentHash = contentHash(tc.Body)
+	} else if inputAbs != tc.TestAbs {
+		in.SourceAbs = tc.TestAbs
+	}
+
+	prev, ok, err := deps.LatestRun(testfile.CaseFile(tc.TestAbs, tc.Params))
+	if err != nil {
+		return "", fmt.Errorf("read previous run of %s: %w", tc.ID, err)
+	}
+	if ok && prev.LogAbs != logAbs && prev.Failed() {
+		if prev.ParseErr != nil {
+			in.PreviousFailure = describeLogError(prev.ParseErr)
+		} else if prev.Reason != "" {
+			in.PreviousFailure = fmt.Sprintf("status=%s: %s", prev.Status, prev.Reason)
+		} else {
+			in.PreviousFailure = fmt.Sprintf("status=%s", prev.Status)
+		}
+	}
+
+	text, err := buildPrompt(tc.Config, tc.RootAbs, in, deps)
+	if err != nil {
+		return "", fmt.Errorf("build prompt for %s: %w", tc.ID, err)
+	}
+	return text, nil
+}
+
+// buildPrompt renders in with the mdtest.yaml template of stack when one
+// applies, else with the built-in prompt.
+func buildPrompt(stack config.Stack, rootAbs string, in prompt.Input, deps Dependencies) (string, error) {
+	if path := stack.PromptTemplate(rootAbs); path != "" {
+		return deps.RenderPromptFile(path, in)
+	}
+	return deps.BuildPrompt(in)
+}
+
+// inlineFileBody returns the content of a Markdown file to embed in an inline
+// mode prompt, or "" in path mode.
+func inlineFileBody(cfg Config, fileAbs string) (string, string, error) {
+	if cfg.PromptMode != PromptModeInline {
+		return "", "", nil
+	}
+	content, err := os.ReadFile(fileAbs)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), contentHash(string(content)), nil
+}
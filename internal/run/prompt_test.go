@@ -0,0 +1,134 @@
+package run
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/internal/config"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+func TestRenderCasePromptFillsDataModel(t *testing.T) {
+	root := t.TempDir()
+	tc := TestCase{
+		ID:      "checkout.test.md[role=admin]",
+		Params:  []testfile.Param{{Name: "role", Value: "admin"}},
+		RootAbs: root,
+		TestAbs: filepath.Join(root, "checkout.test.md"),
+		TestRel: "checkout.test.md",
+		File: testfile.File{
+			Meta:        testfile.Meta{Requires: []string{"browser"}},
+			FrontMatter: map[string]any{"requires": []any{"browser"}},
+		},
+	}
+
+	var got prompt.Input
+	deps := fillDefaults(Dependencies{
+		LatestRun: func(testAbs string) (logs.PreviousRun, bool, error) {
+			if testAbs != filepath.Join(root, "checkout[role=admin].test.md") {
+				t.Fatalf("LatestRun path = %q, want case file", testAbs)
+			}
+			return logs.PreviousRun{LogAbs: "old.log.md", ParseErr: errors.New("missing status key")}, true, nil
+		},
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			got = in
+			return "prompt", nil
+		},
+	})
+
+	if _, err := renderCasePrompt(Config{}, tc, "/tmp/rendered.input.md", "/tmp/new.log.md", 2, deps); err != nil {
+		t.Fatalf("renderCasePrompt returned error: %v", err)
+	}
+	if got.TestID != tc.ID || got.Attempt != 2 || got.SourceAbs != tc.TestAbs || got.Capabilities[0] != "browser" {
+		t.Fatalf("prompt input = %#v", got)
+	}
+	if got.PreviousFailure != "invalid log: missing status key" {
+		t.Fatalf("PreviousFailure = %q", got.PreviousFailure)
+	}
+}
+
+func TestRenderCasePromptGivesPreviousFailureReason(t *testing.T) {
+	root := t.TempDir()
+	tc := TestCase{ID: "a.test.md", RootAbs: root, TestAbs: filepath.Join(root, "a.test.md")}
+
+	var got prompt.Input
+	deps := fillDefaults(Dependencies{
+		LatestRun: func(string) (logs.PreviousRun, bool, error) {
+			return logs.PreviousRun{LogAbs: "old.log.md", Status: logs.StatusFail, Reason: "coupon not applied"}, true, nil
+		},
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			got = in
+			return "prompt", nil
+		},
+	})
+
+	if _, err := renderCasePrompt(Config{}, tc, tc.TestAbs, "/tmp/new.log.md", 1, deps); err != nil {
+		t.Fatalf("renderCasePrompt returned error: %v", err)
+	}
+	if got.PreviousFailure != "status=fail: coupon not applied" {
+		t.Fatalf("PreviousFailure = %q", got.PreviousFailure)
+	}
+}
+
+func TestRenderCasePromptUsesConfiguredTemplate(t *testing.T) {
+	root := t.TempDir()
+	tc := TestCase{
+		ID:      "a.test.md",
+		RootAbs: root,
+		TestAbs: filepath.Join(root, "a.test.md"),
+		Config:  config.Stack{{Path: ".", Prompt: "prompt.tmpl"}},
+	}
+
+	var gotPath string
+	deps := fillDefaults(Dependencies{
+		LatestRun: func(string) (logs.PreviousRun, bool, error) { return logs.PreviousRun{}, false, nil },
+		BuildPrompt: func(prompt.Input) (string, error) {
+			return "", errors.New("built-in prompt should not be used")
+		},
+		RenderPromptFile: func(path string, _ prompt.Input) (string, error) {
+			gotPath = path
+			return "custom", nil
+		},
+	})
+
+	text, err := renderCasePrompt(Config{}, tc, tc.TestAbs, "/tmp/a.log.md", 1, deps)
+	if err != nil {
+		t.Fatalf("renderCasePrompt returned error: %v", err)
+	}
+	if text != "custom" || gotPath != filepath.Join(root, "prompt.tmpl") {
+		t.Fatalf("prompt = %q from %q, want custom template", text, gotPath)
+	}
+}
+
+func TestRenderCasePromptInlineEmbedsBodyAndHash(t *testing.T) {
+	root := t.TempDir()
+	tc := TestCase{
+		ID:      "a.test.md",
+		RootAbs: root,
+		TestAbs: filepath.Join(root, "a.test.md"),
+		File:    testfile.File{Body: "Log in.\n"},
+		Body:    "Log in as qa.\n",
+	}
+
+	var got prompt.Input
+	deps := fillDefaults(Dependencies{
+		LatestRun: func(string) (logs.PreviousRun, bool, error) { return logs.PreviousRun{}, false, nil },
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			got = in
+			return "prompt", nil
+		},
+	})
+
+	if _, err := renderCasePrompt(Config{PromptMode: PromptModeInline}, tc, "/tmp/a.input.md", "/tmp/a.log.md", 1, deps); err != nil {
+		t.Fatalf("renderCasePrompt returned error: %v", err)
+	}
+	if got.TestAbs != tc.TestAbs || got.SourceAbs != "" {
+		t.Fatalf("TestAbs/SourceAbs = %q/%q, want original path only", got.TestAbs, got.SourceAbs)
+	}
+	if got.TestBody != "Log in as qa." || got.ContentHash != contentHash(tc.Body) {
+		t.Fatalf("TestBody/ContentHash = %q/%q", got.TestBody, got.ContentHash)
+	}
+}
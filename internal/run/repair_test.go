@@ -0,0 +1,83 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunRepairsMissingLogByResumingAgent(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		fixOnRepair int
+		wantStatus  TestStatus
+		wantRepairs int
+		wantExecs   int
+	}{
+		{name: "disabled", limit: 0, fixOnRepair: 1, wantStatus: TestFail, wantRepairs: 0, wantExecs: 1},
+		{name: "fixed on second repair", limit: 3, fixOnRepair: 2, wantStatus: TestPass, wantRepairs: 2, wantExecs: 3},
+		{name: "limit reached", limit: 1, fixOnRepair: 2, wantStatus: TestFail, wantRepairs: 1, wantExecs: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+			logAbs := filepath.Join(root, "a.logs", "a.log.md")
+
+			var argvs [][]string
+			var repairs []prompt.RepairInput
+			deps := Dependencies{
+				DiscoverTests: func(string) ([]string, error) { return []string{"a.test.md"}, nil },
+				NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+					return filepath.Dir(logAbs), logAbs, nil
+				},
+				BuildPrompt: func(prompt.Input) (string, error) { return "run", nil },
+				BuildRepairPrompt: func(in prompt.RepairInput) (string, error) {
+					repairs = append(repairs, in)
+					return "repair", nil
+				},
+				MkdirAll: os.MkdirAll,
+				Now:      time.Now,
+				Exec: func(_ context.Context, req ExecRequest) (ExecResult, error) {
+					argvs = append(argvs, req.Argv)
+					if len(argvs)-1 == tt.fixOnRepair {
+						mustWriteFile(t, logAbs, "---\nstatus: pass\n---\nok\n")
+					}
+					return ExecResult{}, nil
+				},
+				Out: io.Discard,
+			}
+
+			suite, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, RepairLimit: tt.limit}, deps)
+			if err != nil {
+				t.Fatalf("Run returned error: %v", err)
+			}
+			got := suite.Results[0]
+			if got.Status != tt.wantStatus || got.Repairs != tt.wantRepairs || len(argvs) != tt.wantExecs {
+				t.Fatalf("status/repairs/execs = %q/%d/%d, want %q/%d/%d", got.Status, got.Repairs, len(argvs), tt.wantStatus, tt.wantRepairs, tt.wantExecs)
+			}
+			if tt.wantRepairs > 0 && suite.Repaired != 1 {
+				t.Fatalf("Repaired = %d, want 1", suite.Repaired)
+			}
+			for i, argv := range argvs[1:] {
+				want := []string{"claude", "-p", "--continue", "--permission-mode", "acceptEdits", "repair"}
+				if !reflect.DeepEqual(argv, want) {
+					t.Fatalf("repair argv = %#v, want %#v", argv, want)
+				}
+				if repairs[i].LogAbs != logAbs || repairs[i].Attempt != i+1 || !strings.Contains(repairs[i].Problem, "no log") {
+					t.Fatalf("repair input = %#v", repairs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+)
+
+// Reporter observes a run. Run calls every registered reporter in order,
+// one event at a time, except for writes to an AgentOutput writer, which may
+// come from the goroutines copying the agent's output. With Config.Jobs
+// above one, events of different tests interleave and may come from
+// different goroutines. Embed BaseReporter
+// to implement only the events of interest.
+type Reporter interface {
+	// SuiteStarted is called once tests are selected and ordered.
+	SuiteStarted(start SuiteStart)
+	// TestScheduled is called for every test, in run order, right after
+	// SuiteStarted.
+	TestScheduled(test ScheduledTest)
+	// TestStarted is called when a test that is not skipped begins, before
+	// its fixtures run.
+	TestStarted(test TestStart)
+	// AgentStarted is called before each agent invocation for a test or
+	// hook.
+	AgentStarted(start AgentStart)
+	// AgentOutput returns where the agent's output for id goes in batch
+	// mode, or nil when the reporter does not want it.
+	AgentOutput(id string) io.Writer
+	// AgentExited is called after each agent invocation.
+	AgentExited(exit AgentExit)
+	// LogParsed is called each time a log written by the agent is read.
+	LogParsed(parsed LogParsed)
+	// TestFinished is called once per test, including skipped ones.
+	TestFinished(result TestResult)
+	// SuiteFinished is called once after the last test and hook.
+	SuiteFinished(suite SuiteResult)
+	// SuiteAborted is called instead of SuiteFinished when the run stops
+	// with err, after any teardown hooks ran. It may come before
+	// SuiteStarted when the suite could not be planned.
+	SuiteAborted(err error)
+}
+
+// SuiteStart describes a run about to start.
+type SuiteStart struct {
+	RootAbs string
+	Config  Config
+	Order   Order
+	// Seed is set for OrderRandom.
+	Seed uint64
+	// IDs lists the tests in run order.
+	IDs []string
+}
+
+// ScheduledTest is a test's place in the run order.
+type ScheduledTest struct {
+	ID      string
+	TestRel string
+	// Index is 1-based.
+	Index int
+}
+
+// TestStart describes a test about to run.
+type TestStart struct {
+	ID      string
+	TestRel string
+	LogAbs  string
+}
+
+// AgentStart describes one agent invocation.
+type AgentStart struct {
+	// ID is the test ID, or the path of a hook file.
+	ID    string
+	Agent agent.Name
+	// Attempt is 1 for the run and counts up with each log repair.
+	Attempt int
+}
+
+// AgentExit is the outcome of one agent invocation.
+type AgentExit struct {
+	ID       string
+	Attempt  int
+	ExitCode int
+}
+
+// LogParsed is the result of reading a log after an agent invocation. Err
+// is set when the log is missing or invalid; Log is then partial.
+type LogParsed struct {
+	ID     string
+	LogAbs string
+	Log    logs.Log
+	Err    error
+}
+
+// BaseReporter implements every Reporter method as a no-op.
+type BaseReporter struct{}
+
+func (BaseReporter) SuiteStarted(SuiteStart)      {}
+func (BaseReporter) TestScheduled(ScheduledTest)  {}
+func (BaseReporter) TestStarted(TestStart)        {}
+func (BaseReporter) AgentStarted(AgentStart)      {}
+func (BaseReporter) AgentOutput(string) io.Writer { return nil }
+func (BaseReporter) AgentExited(AgentExit)        {}
+func (BaseReporter) LogParsed(LogParsed)          {}
+func (BaseReporter) TestFinished(TestResult)      {}
+func (BaseReporter) SuiteFinished(SuiteResult)    {}
+func (BaseReporter) SuiteAborted(error)           {}
+
+// multiReporter forwards events to every reporter in order.
+type multiReporter []Reporter
+
+func (m multiReporter) SuiteStarted(start SuiteStart) {
+	for _, r := range m {
+		r.SuiteStarted(start)
+	}
+}
+
+func (m multiReporter) TestScheduled(test ScheduledTest) {
+	for _, r := range m {
+		r.TestScheduled(test)
+	}
+}
+
+func (m multiReporter) TestStarted(test TestStart) {
+	for _, r := range m {
+		r.TestStarted(test)
+	}
+}
+
+func (m multiReporter) AgentStarted(start AgentStart) {
+	for _, r := range m {
+		r.AgentStarted(start)
+	}
+}
+
+// AgentOutput combines the writers of the reporters that want the output.
+func (m multiReporter) AgentOutput(id string) io.Writer {
+	var writers []io.Writer
+	for _, r := range m {
+		if w := r.AgentOutput(id); w != nil {
+			writers = append(writers, w)
+		}
+	}
+	switch len(writers) {
+	case 0:
+		return nil
+	case 1:
+		return writers[0]
+	default:
+		return io.MultiWriter(writers...)
+	}
+}
+
+func (m multiReporter) AgentExited(exit AgentExit) {
+	for _, r := range m {
+		r.AgentExited(exit)
+	}
+}
+
+func (m multiReporter) LogParsed(parsed LogParsed) {
+	for _, r := range m {
+		r.LogParsed(parsed)
+	}
+}
+
+func (m multiReporter) TestFinished(result TestResult) {
+	for _, r := range m {
+		r.TestFinished(result)
+	}
+}
+
+func (m multiReporter) SuiteFinished(suite SuiteResult) {
+	for _, r := range m {
+		r.SuiteFinished(suite)
+	}
+}
+
+func (m multiReporter) SuiteAborted(err error) {
+	for _, r := range m {
+		r.SuiteAborted(err)
+	}
+}
+
+// lockedReporter serializes events from tests running at once, so that
+// reporters still see one event at a time.
+type lockedReporter struct {
+	mu sync.Mutex
+	r  Reporter
+}
+
+func (l *lockedReporter) SuiteStarted(start SuiteStart) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.SuiteStarted(start)
+}
+
+func (l *lockedReporter) TestScheduled(test ScheduledTest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.TestScheduled(test)
+}
+
+func (l *lockedReporter) TestStarted(test TestStart) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.TestStarted(test)
+}
+
+func (l *lockedReporter) AgentStarted(start AgentStart) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.AgentStarted(start)
+}
+
+// AgentOutput is not locked: its writer is already used from the
+// goroutines copying agent output.
+func (l *lockedReporter) AgentOutput(id string) io.Writer {
+	return l.r.AgentOutput(id)
+}
+
+func (l *lockedReporter) AgentExited(exit AgentExit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.AgentExited(exit)
+}
+
+func (l *lockedReporter) LogParsed(parsed LogParsed) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.LogParsed(parsed)
+}
+
+func (l *lockedReporter) TestFinished(result TestResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.TestFinished(result)
+}
+
+func (l *lockedReporter) SuiteFinished(suite SuiteResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.SuiteFinished(suite)
+}
+
+func (l *lockedReporter) SuiteAborted(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.SuiteAborted(err)
+}
+
+// reporter returns the registered reporters as one.
+func (d Dependencies) reporter() Reporter {
+	return multiReporter(d.Reporters)
+}
+
+// defaultReporter is the reporter Run uses when no reporter is registered.
+func defaultReporter(cfg Config, out io.Writer) Reporter {
+	switch cfg.Format {
+	case FormatTAP:
+		return NewTAPReporter(out)
+	case FormatJSON:
+		return NewJSONReporter(out)
+	default:
+		return NewSummaryReporter(out)
+	}
+}
+
+// NewSummaryReporter returns a reporter that prints failure details and a
+// summary line after the run and nothing while it runs.
+func NewSummaryReporter(out io.Writer) Reporter {
+	return &summaryReporter{out: out}
+}
+
+type summaryReporter struct {
+	BaseReporter
+	out io.Writer
+}
+
+func (r *summaryReporter) SuiteFinished(suite SuiteResult) {
+	for _, result := range suite.Results {
+		if result.Status != TestFail {
+			continue
+		}
+		for _, c := range result.Cases {
+			if c.Status == TestPass {
+				continue
+			}
+			_, _ = fmt.Fprintf(r.out, "%s: case %q failed: %s\n", result.ID, c.Name, c.Reason)
+		}
+		for _, step := range result.Steps {
+			if step.Status != logs.StatusFail {
+				continue
+			}
+			line := fmt.Sprintf("%s: step %q failed", result.ID, step.Name)
+			if step.Reason != "" {
+				line += ": " + step.Reason
+			}
+			_, _ = fmt.Fprintln(r.out, line)
+		}
+	}
+	for _, hook := range suite.Hooks {
+		if hook.Status != TestPass {
+			_, _ = fmt.Fprintf(r.out, "%s %s failed: %s\n", hook.Kind, hook.HookRel, hook.Reason)
+		}
+	}
+	_, _ = fmt.Fprintln(r.out, summaryLine(suite))
+}
+
+// summaryLine counts the results of suite, e.g. "Total: 2, Passed: 1, Failed: 1".
+func summaryLine(suite SuiteResult) string {
+	summary := fmt.Sprintf("Total: %d, Passed: %d, Failed: %d", suite.Total, suite.Passed, suite.Failed)
+	if suite.Skipped > 0 {
+		summary += fmt.Sprintf(", Skipped: %d", suite.Skipped)
+	}
+	if suite.Errored > 0 {
+		summary += fmt.Sprintf(", Errors: %d", suite.Errored)
+	}
+	if suite.XFailed > 0 {
+		summary += fmt.Sprintf(", Expected failures: %d", suite.XFailed)
+	}
+	if suite.XPassed > 0 {
+		summary += fmt.Sprintf(", Unexpected passes: %d", suite.XPassed)
+	}
+	if suite.CaseTotal > 0 {
+		summary += fmt.Sprintf(", Cases passed: %d/%d", suite.CaseTotal-suite.CaseFailed, suite.CaseTotal)
+	}
+	if suite.Repaired > 0 {
+		summary += fmt.Sprintf(", Repaired logs: %d", suite.Repaired)
+	}
+	if suite.Order == OrderRandom {
+		summary += fmt.Sprintf(", Seed: %d", suite.Seed)
+	}
+	return summary
+}
@@ -0,0 +1,231 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+type recordingReporter struct {
+	BaseReporter
+	events []string
+}
+
+func (r *recordingReporter) SuiteStarted(start SuiteStart) {
+	r.events = append(r.events, fmt.Sprintf("suite started %v", start.IDs))
+}
+
+func (r *recordingReporter) TestScheduled(test ScheduledTest) {
+	r.events = append(r.events, fmt.Sprintf("scheduled %d %s", test.Index, test.ID))
+}
+
+func (r *recordingReporter) TestStarted(test TestStart) {
+	r.events = append(r.events, "started "+test.ID)
+}
+
+func (r *recordingReporter) AgentStarted(start AgentStart) {
+	r.events = append(r.events, fmt.Sprintf("agent started %s attempt %d", start.ID, start.Attempt))
+}
+
+func (r *recordingReporter) AgentExited(exit AgentExit) {
+	r.events = append(r.events, fmt.Sprintf("agent exited %s code %d", exit.ID, exit.ExitCode))
+}
+
+func (r *recordingReporter) LogParsed(parsed LogParsed) {
+	r.events = append(r.events, fmt.Sprintf("log parsed %s err=%v", parsed.ID, parsed.Err != nil))
+}
+
+func (r *recordingReporter) TestFinished(result TestResult) {
+	r.events = append(r.events, fmt.Sprintf("finished %s %s", result.ID, result.Status))
+}
+
+func (r *recordingReporter) SuiteFinished(suite SuiteResult) {
+	r.events = append(r.events, fmt.Sprintf("suite finished %d/%d", suite.Passed, suite.Total))
+}
+
+func (r *recordingReporter) SuiteAborted(err error) {
+	r.events = append(r.events, "suite aborted: "+err.Error())
+}
+
+func reporterTestDeps(root string, out io.Writer, reporters ...Reporter) Dependencies {
+	parses := 0
+	return Dependencies{
+		DiscoverTests: func(string) ([]string, error) { return []string{"a.test.md", "b.test.md"}, nil },
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			base := strings.TrimSuffix(filepath.Base(testAbs), ".test.md")
+			logDir := filepath.Join(filepath.Dir(testAbs), base+".logs")
+			return logDir, filepath.Join(logDir, base+".log.md"), nil
+		},
+		ParseLog: func(logAbs string) (logs.Log, error) {
+			if strings.HasSuffix(logAbs, "b.log.md") {
+				return logs.Log{Status: logs.StatusPass}, nil
+			}
+			// a.test.md needs one repair.
+			parses++
+			if parses == 1 {
+				return logs.Log{}, os.ErrNotExist
+			}
+			return logs.Log{Status: logs.StatusPass}, nil
+		},
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         func() time.Time { return time.Date(2026, 2, 10, 14, 30, 0, 0, time.UTC) },
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			return ExecResult{ExitCode: 3}, nil
+		},
+		Out:       out,
+		Reporters: reporters,
+	}
+}
+
+func TestRunSendsEventsToEveryReporter(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "---\nskip: later\n---\n")
+
+	first, second := &recordingReporter{}, &recordingReporter{}
+	var out strings.Builder
+	_, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, RepairLimit: 1}, reporterTestDeps(root, &out, first, second))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	want := []string{
+		"suite started [a.test.md b.test.md]",
+		"scheduled 1 a.test.md",
+		"scheduled 2 b.test.md",
+		"started a.test.md",
+		"agent started a.test.md attempt 1",
+		"agent exited a.test.md code 3",
+		"log parsed a.test.md err=true",
+		"agent started a.test.md attempt 2",
+		"agent exited a.test.md code 3",
+		"log parsed a.test.md err=false",
+		"finished a.test.md pass",
+		"finished b.test.md skip",
+		"suite finished 1/2",
+	}
+	if !reflect.DeepEqual(first.events, want) {
+		t.Fatalf("events =\n%s\nwant\n%s", strings.Join(first.events, "\n"), strings.Join(want, "\n"))
+	}
+	if !reflect.DeepEqual(second.events, want) {
+		t.Fatalf("second reporter events = %#v, want the same events", second.events)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("Out = %q, want nothing when reporters are registered", out.String())
+	}
+}
+
+func TestRunReportsAbortAfterTeardown(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, TeardownHookName), "")
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "")
+
+	rec := &recordingReporter{}
+	var out strings.Builder
+	deps := reporterTestDeps(root, &out, rec)
+	deps.ParseLog = func(string) (logs.Log, error) { return logs.Log{Status: logs.StatusPass}, nil }
+	deps.Exec = func(_ context.Context, req ExecRequest) (ExecResult, error) {
+		if req.ID == "a.test.md" {
+			return ExecResult{}, errors.New("agent crashed")
+		}
+		return ExecResult{}, nil
+	}
+
+	_, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err == nil {
+		t.Fatal("Run returned nil error, want setup error")
+	}
+	want := []string{
+		"suite started [a.test.md b.test.md]",
+		"scheduled 1 a.test.md",
+		"scheduled 2 b.test.md",
+		"started a.test.md",
+		"agent started a.test.md attempt 1",
+		"agent started _teardown.md attempt 1",
+		"agent exited _teardown.md code 0",
+		"log parsed _teardown.md err=false",
+		"suite aborted: " + err.Error(),
+	}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Fatalf("events =\n%s\nwant\n%s", strings.Join(rec.events, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunReportsAbortBeforeSuiteStarts(t *testing.T) {
+	var tap, events strings.Builder
+	deps := reporterTestDeps(t.TempDir(), io.Discard, NewTAPReporter(&tap), NewJSONReporter(&events))
+	deps.DiscoverTests = func(string) ([]string, error) { return nil, nil }
+
+	_, err := Run(context.Background(), Config{Root: t.TempDir(), Agent: agent.ClaudeAgent}, deps)
+	if err == nil {
+		t.Fatal("Run returned nil error, want setup error")
+	}
+	if want := "TAP version 13\nBail out! " + err.Error() + "\n"; tap.String() != want {
+		t.Fatalf("TAP = %q, want %q", tap.String(), want)
+	}
+	var event map[string]any
+	if err := json.Unmarshal([]byte(events.String()), &event); err != nil {
+		t.Fatalf("events = %q: %v", events.String(), err)
+	}
+	if event["event"] != "suite_aborted" || event["error"] != err.Error() {
+		t.Fatalf("event = %v, want suite_aborted with the error", event)
+	}
+}
+
+func TestJSONReporterWritesOneEventPerLine(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "")
+
+	var out strings.Builder
+	_, err := Run(context.Background(), Config{Root: root, Agent: agent.CodexAgent, Format: FormatJSON}, reporterTestDeps(root, &out))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	var names []string
+	for _, event := range events {
+		names = append(names, event["event"].(string))
+	}
+	wantNames := []string{
+		"suite_started", "test_scheduled", "test_scheduled",
+		"test_started", "agent_started", "agent_exited", "log_parsed", "test_finished",
+		"test_started", "agent_started", "agent_exited", "log_parsed", "test_finished",
+		"suite_finished",
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("events = %v, want %v", names, wantNames)
+	}
+	if events[5]["exit_code"] != float64(3) || events[6]["error"] != "agent produced no log" {
+		t.Fatalf("agent events = %v, %v", events[5], events[6])
+	}
+	if events[7]["status"] != "fail" || events[7]["reason"] != "agent produced no log (agent exit code 3)" {
+		t.Fatalf("test_finished = %v", events[7])
+	}
+	counts := events[13]["counts"].(map[string]any)
+	if counts["total"] != float64(2) || counts["passed"] != float64(1) || counts["failed"] != float64(1) {
+		t.Fatalf("suite_finished counts = %v", counts)
+	}
+}
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/config"
 	"github.com/PeronGH/mdtest-cli/internal/logs"
 	"github.com/PeronGH/mdtest-cli/internal/prompt"
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
 )
 
 type TestStatus string
@@ -19,28 +22,95 @@ type TestStatus string
 const (
 	TestPass TestStatus = "pass"
 	TestFail TestStatus = "fail"
+	TestSkip TestStatus = "skip"
+	// TestError means a shell fixture failed, so the verdict is not about
+	// the behavior under test.
+	TestError TestStatus = "error"
+	// TestXFail is a failure of a test marked xfail: in its front matter.
+	TestXFail TestStatus = "xfail"
+	// TestXPass is a pass of a test marked xfail:, which counts as a failure
+	// so that fixed bugs get their marker removed.
+	TestXPass TestStatus = "xpass"
 )
 
 type TestCase struct {
+	// ID is TestRel, plus matrix parameters for expanded cases.
+	ID        string
+	Params    []testfile.Param
 	RootAbs   string
 	TestAbs   string
 	TestRel   string
 	LogDirAbs string
 	LogAbs    string
+	File      testfile.File
+	Config    config.Stack
+	// Body is the test body after include expansion and variable substitution.
+	Body string
+	// Vars holds the resolved variables the body references.
+	Vars []testfile.Param
+	// Context holds project context files for the prompt.
+	Context []prompt.ContextFile
+	// DependsOn holds the IDs of cases that must pass first.
+	DependsOn []string
+	// CaseNames holds the named cases of a multi-case file, if any.
+	CaseNames []string
 }
 
 type TestResult struct {
+	ID      string
 	TestRel string
 	LogAbs  string
 	Status  TestStatus
 	Reason  string
+	Vars    []testfile.Param
+	// ContentHash is the sha256 of the expanded test body given to the agent.
+	ContentHash string
+	// LogState says whether the agent left a usable log; empty when the
+	// agent did not run.
+	LogState LogState
+	// Repairs counts the agent turns spent fixing the log.
+	Repairs int
+	// TranscriptAbs holds the agent's terminal output; FixturesAbs holds
+	// shell fixture output when the test has fixtures.
+	TranscriptAbs string
+	FixturesAbs   string
+	// Cases holds per-case results of a multi-case file.
+	Cases []CaseResult
+	// Elapsed is the wall time spent on the test, fixtures included.
+	Elapsed time.Duration
+	// Steps, Observed, Artifacts and Duration are copied from the log front
+	// matter when the agent recorded them.
+	Steps     []logs.Step
+	Observed  map[string]string
+	Artifacts []string
+	Duration  time.Duration
 }
 
 type SuiteResult struct {
-	Total   int
-	Passed  int
-	Failed  int
+	Total       int
+	Passed      int
+	Failed      int
+	Skipped     int
+	Errored     int
+	HooksFailed int
+	// XFailed counts expected failures; XPassed counts unexpected passes.
+	XFailed int
+	XPassed int
+	// Repaired counts tests whose log needed at least one repair turn.
+	Repaired int
+	// CaseTotal and CaseFailed count the named cases of multi-case files.
+	CaseTotal  int
+	CaseFailed int
+	// StartedAt and Elapsed time the whole run.
+	StartedAt time.Time
+	Elapsed   time.Duration
+	// RootAbs is the suite root; Config is the configuration of the run.
+	RootAbs string
+	Config  Config
+	Order   Order
+	Seed    uint64
 	Results []TestResult
+	Hooks   []HookResult
 }
 
 type Config struct {
@@ -49,12 +119,55 @@ type Config struct {
 	Agent                      agent.Name
 	Interactive                bool
 	DangerouslyAllowAllActions bool
+	// Order selects the execution order; empty means lexical.
+	Order Order
+	// Seed drives OrderRandom. Zero picks a fresh seed, reported in SuiteResult.
+	Seed uint64
+	// Vars are --var values for ${NAME} placeholders in test bodies.
+	Vars map[string]string
+	// PromptMode selects path or inline prompts; empty means path.
+	PromptMode PromptMode
+	// StrictLog fails tests whose log breaks any rule of the log schema,
+	// not only those without a valid status.
+	StrictLog bool
+	// RunXFail runs tests marked skip: or xfail: as normal tests.
+	RunXFail bool
+	// RepairLimit is how many times the agent is resumed to fix a log that
+	// is missing or cannot be parsed. Zero disables repairs.
+	RepairLimit int
+	// Jobs is how many tests run at once, as dependencies allow. Zero or one
+	// runs them one at a time.
+	Jobs int
+	// Format selects the progress output on Dependencies.Out; empty means
+	// text.
+	Format Format
+	// RecordDir, when set, saves every agent invocation to a cassette in
+	// this directory.
+	RecordDir string
+	// ReplayDir, when set, plays back the cassettes recorded in this
+	// directory instead of calling Dependencies.Exec.
+	ReplayDir string
 }
 
 type ExecRequest struct {
 	RootAbs     string
 	Argv        []string
 	Interactive bool
+	// ID is the test ID, or the path of a hook file, the agent runs for.
+	ID string
+	// TestAbs is the .test.md or hook file the agent runs.
+	TestAbs string
+	// LogAbs is where the prompt asks the agent to write its log.
+	LogAbs string
+	// Prompt is the prompt passed to the agent in Argv.
+	Prompt string
+	// Attempt is 1 for the run and counts up with each log repair.
+	Attempt int
+	// Transcript receives a copy of the agent's terminal output.
+	Transcript io.Writer
+	// Output receives the agent's stdout and stderr in batch mode; nil
+	// leaves them on the terminal.
+	Output io.Writer
 }
 
 type ExecResult struct {
@@ -63,15 +176,37 @@ type ExecResult struct {
 
 type ExecFunc func(ctx context.Context, req ExecRequest) (ExecResult, error)
 
+type ShellRequest struct {
+	RootAbs string
+	Command string
+	Output  io.Writer
+}
+
+type ShellFunc func(ctx context.Context, req ShellRequest) (ExecResult, error)
+
 type Dependencies struct {
 	DiscoverTests func(rootAbs string) ([]string, error)
 	NextLogPath   func(testAbs string, at time.Time) (string, string, error)
-	ParseStatus   func(path string) (logs.Status, error)
-	BuildPrompt   func(testAbs string, logAbs string) string
-	MkdirAll      func(path string, perm os.FileMode) error
-	Now           func() time.Time
-	Exec          ExecFunc
-	Out           io.Writer
+	ParseLog      func(path string) (logs.Log, error)
+	// ValidateLog checks a log against the full schema for --strict-log.
+	ValidateLog func(path string) ([]logs.Diagnostic, error)
+	LatestRun   func(testAbs string) (logs.PreviousRun, bool, error)
+	ReadTest    func(testAbs string) (testfile.File, error)
+	LoadConfig  func(rootAbs string, dirRel string) (config.Stack, error)
+	LookupEnv   func(key string) (string, bool)
+	BuildPrompt func(in prompt.Input) (string, error)
+	// RenderPromptFile renders a prompt template configured in mdtest.yaml.
+	RenderPromptFile func(path string, in prompt.Input) (string, error)
+	// BuildRepairPrompt renders the prompt asking the agent to fix its log.
+	BuildRepairPrompt func(in prompt.RepairInput) (string, error)
+	MkdirAll          func(path string, perm os.FileMode) error
+	Now               func() time.Time
+	Exec              ExecFunc
+	Shell             ShellFunc
+	Out               io.Writer
+	// Reporters observe the run. With none, Run reports to Out in
+	// Config.Format.
+	Reporters []Reporter
 }
 
 type SetupError struct {
@@ -88,100 +223,501 @@ func (e *SetupError) Unwrap() error {
 
 func DefaultDependencies(out io.Writer, execFn ExecFunc) Dependencies {
 	return Dependencies{
-		DiscoverTests: DiscoverTests,
-		NextLogPath:   logs.NextLogPath,
-		ParseStatus:   logs.ParseStatus,
-		BuildPrompt:   prompt.Render,
-		MkdirAll:      os.MkdirAll,
-		Now:           time.Now,
-		Exec:          execFn,
-		Out:           out,
+		DiscoverTests:     DiscoverTests,
+		NextLogPath:       logs.NextLogPath,
+		ParseLog:          logs.ParseLog,
+		ValidateLog:       logs.Validate,
+		LatestRun:         logs.LatestRun,
+		ReadTest:          testfile.Read,
+		LoadConfig:        config.Load,
+		LookupEnv:         os.LookupEnv,
+		BuildPrompt:       prompt.Render,
+		BuildRepairPrompt: prompt.RenderRepair,
+		RenderPromptFile:  prompt.RenderFile,
+		MkdirAll:          os.MkdirAll,
+		Now:               time.Now,
+		Exec:              execFn,
+		Shell:             ProcessShell,
+		Out:               out,
 	}
 }
 
 func Run(ctx context.Context, cfg Config, deps Dependencies) (SuiteResult, error) {
 	deps = fillDefaults(deps)
+	switch {
+	case cfg.RecordDir != "" && cfg.ReplayDir != "":
+		return SuiteResult{}, &SetupError{Err: fmt.Errorf("cannot record and replay in the same run")}
+	case cfg.ReplayDir != "":
+		deps.Exec = ReplayExec(cfg.ReplayDir)
+	case cfg.RecordDir != "":
+		deps.Exec = RecordExec(cfg.RecordDir, deps.Exec)
+	}
+	if err := checkJobs(cfg); err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+	if len(deps.Reporters) == 0 {
+		deps.Reporters = []Reporter{defaultReporter(cfg, deps.Out)}
+	}
+	if cfg.Jobs > 1 {
+		deps.Reporters = []Reporter{&lockedReporter{r: deps.reporter()}}
+	}
+
+	suite, err := runSuite(ctx, cfg, deps)
+	if err != nil {
+		deps.reporter().SuiteAborted(err)
+		return SuiteResult{}, err
+	}
+	return suite, nil
+}
+
+// runSuite is Run once the reporters are in place. Hooks still entered
+// when it returns have been torn down.
+func runSuite(ctx context.Context, cfg Config, deps Dependencies) (SuiteResult, error) {
+	rootAbs, err := resolveRoot(cfg)
+	if err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+	tests, err := selectTests(rootAbs, cfg, deps)
+	if err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+	ids, expanded, err := planCases(rootAbs, tests, cfg, deps)
+	if err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+
+	order, err := ParseOrder(string(cfg.Order))
+	if err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+	seed := cfg.Seed
+	if order == OrderRandom && seed == 0 {
+		seed = uint64(deps.Now().UnixNano())
+		if seed == 0 {
+			seed = 1
+		}
+	}
+	ids, err = orderTests(ids, order, seed, func(id string) (logs.PreviousRun, bool, error) {
+		tc := expanded[id]
+		return deps.LatestRun(testfile.CaseFile(tc.TestAbs, tc.Params))
+	})
+	if err != nil {
+		return SuiteResult{}, &SetupError{Err: fmt.Errorf("order tests: %w", err)}
+	}
+	ids, err = scheduleTests(ids, expanded)
+	if err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+
+	cases := make([]TestCase, 0, len(ids))
+	caseRels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		cases = append(cases, expanded[id])
+		caseRels = append(caseRels, expanded[id].TestRel)
+	}
+
+	suite := SuiteResult{
+		Total:   len(cases),
+		Order:   order,
+		Results: make([]TestResult, 0, len(cases)),
+		RootAbs: rootAbs,
+		Config:  cfg,
+	}
+	if order == OrderRandom {
+		suite.Seed = seed
+	}
+	hooks := newHookRunner(rootAbs, caseRels, func(ctx context.Context, fileRel string) (agentOutcome, error) {
+		return runAgentFile(ctx, cfg, rootAbs, fileRel, deps)
+	})
+	defer func() {
+		_ = hooks.finish(ctx)
+	}()
+
+	suite.StartedAt = deps.Now()
+	deps.reporter().SuiteStarted(SuiteStart{RootAbs: rootAbs, Config: cfg, Order: order, Seed: suite.Seed, IDs: ids})
+	for i, tc := range cases {
+		deps.reporter().TestScheduled(ScheduledTest{ID: tc.ID, TestRel: tc.TestRel, Index: i + 1})
+	}
+	results := make([]TestResult, len(cases))
+	err = runCases(ctx, cfg, cases, hooks, deps, func(i int, result TestResult) error {
+		switch result.Status {
+		case TestPass:
+			suite.Passed++
+		case TestSkip:
+			suite.Skipped++
+		case TestError:
+			suite.Errored++
+		case TestXFail:
+			suite.XFailed++
+		case TestXPass:
+			suite.XPassed++
+		default:
+			suite.Failed++
+		}
+		if result.Repairs > 0 {
+			suite.Repaired++
+		}
+		suite.CaseTotal += len(result.Cases)
+		suite.CaseFailed += failedCases(result.Cases)
+		results[i] = result
+		deps.reporter().TestFinished(result)
+		return hooks.leave(ctx, cases[i].TestRel)
+	})
+	if err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+	// Results keep the schedule order even when tests finish out of order.
+	suite.Results = append(suite.Results, results...)
+	if err := hooks.finish(ctx); err != nil {
+		return SuiteResult{}, &SetupError{Err: err}
+	}
+	suite.Elapsed = deps.Now().Sub(suite.StartedAt)
+
+	suite.Hooks = hooks.results
+	for _, hook := range suite.Hooks {
+		if hook.Status != TestPass {
+			suite.HooksFailed++
+		}
+	}
+	deps.reporter().SuiteFinished(suite)
+	return suite, nil
+}
+
+type agentOutcome struct {
+	LogAbs        string
+	TranscriptAbs string
+	Log           logs.Log
+	ParseErr      error
+	ExitCode      int
+	// Repairs counts the agent turns spent fixing the log.
+	Repairs int
+}
+
+func (o agentOutcome) passed() bool {
+	return o.ParseErr == nil && o.Log.Status == logs.StatusPass
+}
+
+func (o agentOutcome) reason() string {
+	if o.ParseErr != nil {
+		if o.Repairs > 0 {
+			return fmt.Sprintf("%s after %d repair(s) (agent exit code %d)", describeLogError(o.ParseErr), o.Repairs, o.ExitCode)
+		}
+		return fmt.Sprintf("%s (agent exit code %d)", describeLogError(o.ParseErr), o.ExitCode)
+	}
+	if o.Log.Reason != "" {
+		return fmt.Sprintf("status=%s: %s (agent exit code %d)", o.Log.Status, o.Log.Reason, o.ExitCode)
+	}
+	return fmt.Sprintf("status=%s (agent exit code %d)", o.Log.Status, o.ExitCode)
+}
 
+func resolveRoot(cfg Config) (string, error) {
 	root := cfg.Root
 	if root == "" {
 		root = "."
 	}
 	rootAbs, err := filepath.Abs(root)
 	if err != nil {
-		return SuiteResult{}, &SetupError{Err: fmt.Errorf("resolve root: %w", err)}
+		return "", fmt.Errorf("resolve root: %w", err)
 	}
+	return rootAbs, nil
+}
 
+// selectTests returns the explicit targets of cfg, or all discovered tests.
+func selectTests(rootAbs string, cfg Config, deps Dependencies) ([]string, error) {
 	var tests []string
+	var err error
 	if len(cfg.Files) > 0 {
 		tests, err = ResolveExplicitTests(rootAbs, cfg.Files)
 		if err != nil {
-			return SuiteResult{}, &SetupError{Err: fmt.Errorf("resolve explicit test targets: %w", err)}
+			return nil, fmt.Errorf("resolve explicit test targets: %w", err)
 		}
 	} else {
 		tests, err = deps.DiscoverTests(rootAbs)
 		if err != nil {
-			return SuiteResult{}, &SetupError{Err: fmt.Errorf("discover tests: %w", err)}
+			return nil, fmt.Errorf("discover tests: %w", err)
 		}
-		sort.Strings(tests)
 	}
 	if len(tests) == 0 {
-		return SuiteResult{}, &SetupError{Err: fmt.Errorf("no tests found under %s", rootAbs)}
+		return nil, fmt.Errorf("no tests found under %s", rootAbs)
 	}
+	return tests, nil
+}
 
-	suite := SuiteResult{
-		Total:   len(tests),
-		Results: make([]TestResult, 0, len(tests)),
-	}
+// planCases reads the selected tests and their prerequisites, expands
+// matrices and resolves variables. It returns case IDs in lexical file order.
+func planCases(rootAbs string, tests []string, cfg Config, deps Dependencies) ([]string, map[string]TestCase, error) {
+	planned := make(map[string]TestCase, len(tests))
 	for _, testRel := range tests {
-		testAbs := filepath.Join(rootAbs, filepath.FromSlash(testRel))
-		logDir, logAbs, err := deps.NextLogPath(testAbs, deps.Now().UTC())
+		tc, err := planTest(rootAbs, testRel, deps)
 		if err != nil {
-			return SuiteResult{}, &SetupError{Err: fmt.Errorf("next log path for %s: %w", testRel, err)}
-		}
-		if err := deps.MkdirAll(logDir, 0o755); err != nil {
-			return SuiteResult{}, &SetupError{Err: fmt.Errorf("create log dir for %s: %w", testRel, err)}
+			return nil, nil, err
 		}
+		planned[testRel] = tc
+	}
+	tests, err := resolveDependencies(rootAbs, planned, deps)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids, expanded := expandCases(tests, planned)
+	if err := resolveVars(ids, expanded, cfg, deps); err != nil {
+		return nil, nil, err
+	}
+	if err := resolveCaseNames(ids, expanded); err != nil {
+		return nil, nil, err
+	}
+	return ids, expanded, nil
+}
 
-		promptText := deps.BuildPrompt(testAbs, logAbs)
-		argv, err := agent.CommandArgs(cfg.Agent, promptText, agent.CommandOptions{
-			Interactive:                cfg.Interactive,
-			DangerouslyAllowAllActions: cfg.DangerouslyAllowAllActions,
-		})
-		if err != nil {
-			return SuiteResult{}, &SetupError{Err: fmt.Errorf("build command for %s: %w", testRel, err)}
-		}
+// planTest loads the test's front matter and directory config.
+func planTest(rootAbs string, testRel string, deps Dependencies) (TestCase, error) {
+	testAbs := filepath.Join(rootAbs, filepath.FromSlash(testRel))
+	file, err := deps.ReadTest(testAbs)
+	if err != nil {
+		return TestCase{}, fmt.Errorf("read %s: %w", testRel, err)
+	}
+	stack, err := deps.LoadConfig(rootAbs, path.Dir(testRel))
+	if err != nil {
+		return TestCase{}, fmt.Errorf("config for %s: %w", testRel, err)
+	}
+	body, err := expandIncludes(rootAbs, testAbs, file.Body, file.BodyOffset)
+	if err != nil {
+		return TestCase{}, err
+	}
+	contextFiles, err := loadContext(rootAbs, stack)
+	if err != nil {
+		return TestCase{}, fmt.Errorf("context for %s: %w", testRel, err)
+	}
+	return TestCase{
+		ID:      testRel,
+		RootAbs: rootAbs,
+		TestAbs: testAbs,
+		TestRel: testRel,
+		File:    file,
+		Config:  stack,
+		Body:    body,
+		Context: contextFiles,
+	}, nil
+}
 
-		execResult, err := deps.Exec(ctx, ExecRequest{
-			RootAbs:     rootAbs,
-			Argv:        argv,
-			Interactive: cfg.Interactive,
-		})
+// runTest runs the test's shell fixtures around the agent invocation.
+func runTest(ctx context.Context, cfg Config, tc TestCase, deps Dependencies) (TestResult, error) {
+	logDir, logAbs, err := prepareLog(testfile.CaseFile(tc.TestAbs, tc.Params), tc.ID, deps)
+	if err != nil {
+		return TestResult{}, err
+	}
+	tc.LogDirAbs, tc.LogAbs = logDir, logAbs
+	deps.reporter().TestStarted(TestStart{ID: tc.ID, TestRel: tc.TestRel, LogAbs: logAbs})
+	result := TestResult{
+		ID:          tc.ID,
+		TestRel:     tc.TestRel,
+		LogAbs:      logAbs,
+		Vars:        tc.Vars,
+		ContentHash: contentHash(tc.Body),
+	}
+	inputAbs, err := writeRenderedTest(tc)
+	if err != nil {
+		return TestResult{}, err
+	}
+
+	outputAbs := fixtureOutputPath(logAbs)
+	before := append(tc.Config.Before(), tc.File.Meta.Before...)
+	after := append(append([]string(nil), tc.File.Meta.After...), tc.Config.After()...)
+	if len(before)+len(after) > 0 {
+		result.FixturesAbs = outputAbs
+	}
+
+	failure, err := runFixtures(ctx, fixtureBefore, before, tc.RootAbs, outputAbs, deps)
+	if err != nil {
+		return TestResult{}, fmt.Errorf("%s: %w", tc.ID, err)
+	}
+	if failure != "" {
+		result.Status = TestError
+		result.Reason = failure
+	} else {
+		promptText, err := renderCasePrompt(cfg, tc, inputAbs, tc.LogAbs, 1, deps)
 		if err != nil {
-			return SuiteResult{}, &SetupError{Err: fmt.Errorf("execute %s: %w", testRel, err)}
+			_, _ = runFixtures(context.WithoutCancel(ctx), fixtureAfter, after, tc.RootAbs, outputAbs, deps)
+			return TestResult{}, err
 		}
-
-		status, parseErr := deps.ParseStatus(logAbs)
-		result := TestResult{
-			TestRel: testRel,
-			LogAbs:  logAbs,
+		outcome, err := runAgent(ctx, cfg, tc.RootAbs, tc.TestAbs, promptText, tc.LogAbs, tc.ID, deps)
+		if err != nil {
+			_, _ = runFixtures(context.WithoutCancel(ctx), fixtureAfter, after, tc.RootAbs, outputAbs, deps)
+			return TestResult{}, err
 		}
-		if parseErr != nil {
-			result.Status = TestFail
-			result.Reason = fmt.Sprintf("log parse error: %v (agent exit code %d)", parseErr, execResult.ExitCode)
-			suite.Failed++
-		} else if status == logs.StatusPass {
+		result.TranscriptAbs = outcome.TranscriptAbs
+		result.LogState = logState(outcome.ParseErr)
+		result.Repairs = outcome.Repairs
+		result.Steps = outcome.Log.Steps
+		result.Observed = outcome.Log.Observed
+		result.Artifacts = outcome.Log.Artifacts
+		result.Duration = outcome.Log.Duration
+		if outcome.passed() {
 			result.Status = TestPass
-			suite.Passed++
 		} else {
 			result.Status = TestFail
-			result.Reason = fmt.Sprintf("status=%s (agent exit code %d)", status, execResult.ExitCode)
-			suite.Failed++
+			result.Reason = outcome.reason()
+		}
+		if len(tc.CaseNames) > 0 {
+			result.Cases = caseResults(tc.CaseNames, outcome)
+			if failed := failedCases(result.Cases); failed > 0 && result.Status == TestPass {
+				result.Status = TestFail
+				result.Reason = fmt.Sprintf("%d of %d cases failed (agent exit code %d)", failed, len(result.Cases), outcome.ExitCode)
+			}
 		}
-		suite.Results = append(suite.Results, result)
 	}
 
-	_, _ = fmt.Fprintf(deps.Out, "Total: %d, Passed: %d, Failed: %d\n", suite.Total, suite.Passed, suite.Failed)
-	return suite, nil
+	failure, err = runFixtures(context.WithoutCancel(ctx), fixtureAfter, after, tc.RootAbs, outputAbs, deps)
+	if err != nil {
+		return TestResult{}, fmt.Errorf("%s: %w", tc.ID, err)
+	}
+	if failure != "" && result.Status != TestError {
+		if result.Reason != "" {
+			failure += "; test " + result.Reason
+		}
+		result.Status = TestError
+		result.Reason = failure
+	}
+	return result, nil
+}
+
+// runAgentFile has the agent execute one Markdown file and reads back its log.
+func runAgentFile(ctx context.Context, cfg Config, rootAbs string, fileRel string, deps Dependencies) (agentOutcome, error) {
+	fileAbs := filepath.Join(rootAbs, filepath.FromSlash(fileRel))
+	stack, err := deps.LoadConfig(rootAbs, path.Dir(fileRel))
+	if err != nil {
+		return agentOutcome{}, fmt.Errorf("config for %s: %w", fileRel, err)
+	}
+	contextFiles, err := loadContext(rootAbs, stack)
+	if err != nil {
+		return agentOutcome{}, fmt.Errorf("context for %s: %w", fileRel, err)
+	}
+	body, hash, err := inlineFileBody(cfg, fileAbs)
+	if err != nil {
+		return agentOutcome{}, fmt.Errorf("read %s: %w", fileRel, err)
+	}
+	_, logAbs, err := prepareLog(fileAbs, fileRel, deps)
+	if err != nil {
+		return agentOutcome{}, err
+	}
+	promptText, err := buildPrompt(stack, rootAbs, prompt.Input{
+		TestAbs:     fileAbs,
+		TestBody:    body,
+		ContentHash: hash,
+		LogAbs:      logAbs,
+		TestID:      fileRel,
+		Attempt:     1,
+		Context:     contextFiles,
+	}, deps)
+	if err != nil {
+		return agentOutcome{}, fmt.Errorf("build prompt for %s: %w", fileRel, err)
+	}
+	return runAgent(ctx, cfg, rootAbs, fileAbs, promptText, logAbs, fileRel, deps)
+}
+
+func prepareLog(fileAbs string, fileRel string, deps Dependencies) (string, string, error) {
+	logDir, logAbs, err := deps.NextLogPath(fileAbs, deps.Now().UTC())
+	if err != nil {
+		return "", "", fmt.Errorf("next log path for %s: %w", fileRel, err)
+	}
+	if err := deps.MkdirAll(logDir, 0o755); err != nil {
+		return "", "", fmt.Errorf("create log dir for %s: %w", fileRel, err)
+	}
+	return logDir, logAbs, nil
+}
+
+// transcriptPath returns where the agent's terminal output for logAbs is
+// captured.
+func transcriptPath(logAbs string) string {
+	return strings.TrimSuffix(logAbs, ".log.md") + ".transcript.txt"
+}
+
+func runAgent(
+	ctx context.Context,
+	cfg Config,
+	rootAbs string,
+	testAbs string,
+	promptText string,
+	logAbs string,
+	label string,
+	deps Dependencies,
+) (agentOutcome, error) {
+	transcriptAbs := transcriptPath(logAbs)
+	transcript, err := os.OpenFile(transcriptAbs, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return agentOutcome{}, fmt.Errorf("open transcript for %s: %w", label, err)
+	}
+	defer func() {
+		_ = transcript.Close()
+	}()
+
+	base := ExecRequest{
+		RootAbs:    rootAbs,
+		ID:         label,
+		TestAbs:    testAbs,
+		LogAbs:     logAbs,
+		Transcript: transcript,
+	}
+	execResult, err := execAgent(ctx, cfg, base, promptText, 1, deps)
+	if err != nil {
+		return agentOutcome{}, fmt.Errorf("execute %s: %w", label, err)
+	}
+
+	outcome := agentOutcome{LogAbs: logAbs, TranscriptAbs: transcriptAbs, ExitCode: execResult.ExitCode}
+	outcome.Log, outcome.ParseErr = readLog(cfg, logAbs, deps)
+	deps.reporter().LogParsed(LogParsed{ID: label, LogAbs: logAbs, Log: outcome.Log, Err: outcome.ParseErr})
+	for outcome.ParseErr != nil && outcome.Repairs < cfg.RepairLimit {
+		repairPrompt, err := deps.BuildRepairPrompt(prompt.RepairInput{
+			LogAbs:  logAbs,
+			Problem: describeLogError(outcome.ParseErr),
+			Attempt: outcome.Repairs + 1,
+		})
+		if err != nil {
+			return agentOutcome{}, fmt.Errorf("build repair prompt for %s: %w", label, err)
+		}
+		_, _ = fmt.Fprintf(transcript, "\n--- log repair %d ---\n", outcome.Repairs+1)
+		execResult, err = execAgent(ctx, cfg, base, repairPrompt, outcome.Repairs+2, deps)
+		if err != nil {
+			return agentOutcome{}, fmt.Errorf("repair log of %s: %w", label, err)
+		}
+		outcome.Repairs++
+		outcome.ExitCode = execResult.ExitCode
+		outcome.Log, outcome.ParseErr = readLog(cfg, logAbs, deps)
+		deps.reporter().LogParsed(LogParsed{ID: label, LogAbs: logAbs, Log: outcome.Log, Err: outcome.ParseErr})
+	}
+	return outcome, nil
+}
+
+// execAgent runs the agent CLI with promptText for the test of req. Attempts
+// after the first continue the agent's latest session in req.RootAbs, which
+// is the one that just ran.
+func execAgent(
+	ctx context.Context,
+	cfg Config,
+	req ExecRequest,
+	promptText string,
+	attempt int,
+	deps Dependencies,
+) (ExecResult, error) {
+	argv, err := agent.CommandArgs(cfg.Agent, promptText, agent.CommandOptions{
+		Interactive:                cfg.Interactive,
+		DangerouslyAllowAllActions: cfg.DangerouslyAllowAllActions,
+		Resume:                     attempt > 1,
+	})
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("build command: %w", err)
+	}
+	deps.reporter().AgentStarted(AgentStart{ID: req.ID, Agent: cfg.Agent, Attempt: attempt})
+	req.Argv = argv
+	req.Interactive = cfg.Interactive
+	req.Prompt = promptText
+	req.Attempt = attempt
+	req.Output = deps.reporter().AgentOutput(req.ID)
+	result, err := deps.Exec(ctx, req)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	deps.reporter().AgentExited(AgentExit{ID: req.ID, Attempt: attempt, ExitCode: result.ExitCode})
+	return result, nil
 }
 
 func fillDefaults(deps Dependencies) Dependencies {
@@ -191,12 +727,33 @@ func fillDefaults(deps Dependencies) Dependencies {
 	if deps.NextLogPath == nil {
 		deps.NextLogPath = logs.NextLogPath
 	}
-	if deps.ParseStatus == nil {
-		deps.ParseStatus = logs.ParseStatus
+	if deps.ParseLog == nil {
+		deps.ParseLog = logs.ParseLog
+	}
+	if deps.BuildRepairPrompt == nil {
+		deps.BuildRepairPrompt = prompt.RenderRepair
+	}
+	if deps.ValidateLog == nil {
+		deps.ValidateLog = logs.Validate
+	}
+	if deps.LatestRun == nil {
+		deps.LatestRun = logs.LatestRun
+	}
+	if deps.ReadTest == nil {
+		deps.ReadTest = testfile.Read
+	}
+	if deps.LoadConfig == nil {
+		deps.LoadConfig = config.Load
+	}
+	if deps.LookupEnv == nil {
+		deps.LookupEnv = os.LookupEnv
 	}
 	if deps.BuildPrompt == nil {
 		deps.BuildPrompt = prompt.Render
 	}
+	if deps.RenderPromptFile == nil {
+		deps.RenderPromptFile = prompt.RenderFile
+	}
 	if deps.MkdirAll == nil {
 		deps.MkdirAll = os.MkdirAll
 	}
@@ -208,6 +765,11 @@ func fillDefaults(deps Dependencies) Dependencies {
 			return ExecResult{}, fmt.Errorf("executor is not configured")
 		}
 	}
+	if deps.Shell == nil {
+		deps.Shell = func(context.Context, ShellRequest) (ExecResult, error) {
+			return ExecResult{}, fmt.Errorf("shell runner is not configured")
+		}
+	}
 	if deps.Out == nil {
 		deps.Out = io.Discard
 	}
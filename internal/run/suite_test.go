@@ -13,6 +13,7 @@ import (
 
 	"github.com/PeronGH/mdtest-cli/internal/agent"
 	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
 )
 
 func TestRunReturnsSetupErrorWhenNoTests(t *testing.T) {
@@ -20,8 +21,8 @@ func TestRunReturnsSetupErrorWhenNoTests(t *testing.T) {
 	deps := Dependencies{
 		DiscoverTests: func(string) ([]string, error) { return nil, nil },
 		NextLogPath:   logs.NextLogPath,
-		ParseStatus:   logs.ParseStatus,
-		BuildPrompt:   func(string, string) string { return "" },
+		ParseLog:      logs.ParseLog,
+		BuildPrompt:   func(prompt.Input) (string, error) { return "", nil },
 		MkdirAll:      os.MkdirAll,
 		Now:           time.Now,
 		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
@@ -57,16 +58,16 @@ func TestRunSortsTestsAndContinuesOnParseErrors(t *testing.T) {
 			logAbs := filepath.Join(logDir, base+".log.md")
 			return logDir, logAbs, nil
 		},
-		ParseStatus: func(logAbs string) (logs.Status, error) {
+		ParseLog: func(logAbs string) (logs.Log, error) {
 			if strings.HasSuffix(logAbs, "a.log.md") {
-				return "", errors.New("bad log")
+				return logs.Log{}, errors.New("bad log")
 			}
-			return logs.StatusPass, nil
+			return logs.Log{Status: logs.StatusPass}, nil
 		},
-		BuildPrompt: func(testAbs string, logAbs string) string {
-			prompt := testAbs + " -> " + logAbs
-			prompts = append(prompts, prompt)
-			return prompt
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			text := in.TestAbs + " -> " + in.LogAbs
+			prompts = append(prompts, text)
+			return text, nil
 		},
 		MkdirAll: os.MkdirAll,
 		Now: func() time.Time {
@@ -113,8 +114,8 @@ func TestRunReturnsSetupErrorWhenExecFails(t *testing.T) {
 			logDir := filepath.Join(filepath.Dir(testAbs), "only.logs")
 			return logDir, filepath.Join(logDir, "only.log.md"), nil
 		},
-		ParseStatus: func(string) (logs.Status, error) { return logs.StatusPass, nil },
-		BuildPrompt: func(string, string) string { return "prompt" },
+		ParseLog:    func(string) (logs.Log, error) { return logs.Log{Status: logs.StatusPass}, nil },
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
 		MkdirAll:    os.MkdirAll,
 		Now:         time.Now,
 		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
@@ -151,11 +152,11 @@ func TestRunUsesExplicitFilesAndSkipsDiscovery(t *testing.T) {
 			logAbs := filepath.Join(logDir, base+".log.md")
 			return logDir, logAbs, nil
 		},
-		ParseStatus: func(string) (logs.Status, error) {
-			return logs.StatusPass, nil
+		ParseLog: func(string) (logs.Log, error) {
+			return logs.Log{Status: logs.StatusPass}, nil
 		},
-		BuildPrompt: func(testAbs string, logAbs string) string {
-			return testAbs + " => " + logAbs
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			return in.TestAbs + " => " + in.LogAbs, nil
 		},
 		MkdirAll: os.MkdirAll,
 		Now: func() time.Time {
@@ -201,3 +202,125 @@ func TestRunUsesExplicitFilesAndSkipsDiscovery(t *testing.T) {
 		t.Fatalf("first argv = %#v, want codex exec ...", seenArgs[0])
 	}
 }
+
+func TestRunReportsGeneratedSeedForRandomOrder(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+
+	var out strings.Builder
+	deps := Dependencies{
+		DiscoverTests: func(string) ([]string, error) { return []string{"a.test.md"}, nil },
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			logDir := filepath.Join(filepath.Dir(testAbs), "a.logs")
+			return logDir, filepath.Join(logDir, "a.log.md"), nil
+		},
+		ParseLog:    func(string) (logs.Log, error) { return logs.Log{Status: logs.StatusPass}, nil },
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+		MkdirAll:    os.MkdirAll,
+		Now: func() time.Time {
+			return time.Unix(0, 12345)
+		},
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			return ExecResult{}, nil
+		},
+		Out: &out,
+	}
+
+	result, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, Order: OrderRandom}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Seed != 12345 {
+		t.Fatalf("Seed = %d, want 12345", result.Seed)
+	}
+	if !strings.Contains(out.String(), "Seed: 12345") {
+		t.Fatalf("summary = %q, want seed", out.String())
+	}
+}
+
+func TestRunCopiesLogEvidenceIntoResult(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+
+	parsed := logs.Log{
+		Status: logs.StatusFail,
+		Reason: "coupon discount was wrong",
+		Steps: []logs.Step{
+			{Name: "add to cart", Status: logs.StatusPass},
+			{Name: "apply coupon", Status: logs.StatusFail, Reason: "got 15%"},
+		},
+		Observed:  map[string]string{"discount": "15%"},
+		Artifacts: []string{"coupon.png"},
+		Duration:  time.Minute,
+	}
+	var out strings.Builder
+	deps := Dependencies{
+		DiscoverTests: func(string) ([]string, error) { return []string{"a.test.md"}, nil },
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			logDir := filepath.Join(filepath.Dir(testAbs), "a.logs")
+			return logDir, filepath.Join(logDir, "a.log.md"), nil
+		},
+		ParseLog:    func(string) (logs.Log, error) { return parsed, nil },
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         time.Now,
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			return ExecResult{ExitCode: 0}, nil
+		},
+		Out: &out,
+	}
+
+	suite, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := suite.Results[0]
+	if !reflect.DeepEqual(got.Steps, parsed.Steps) || !reflect.DeepEqual(got.Observed, parsed.Observed) ||
+		!reflect.DeepEqual(got.Artifacts, parsed.Artifacts) || got.Duration != parsed.Duration {
+		t.Fatalf("result = %#v, want log evidence", got)
+	}
+	if got.Reason != "status=fail: coupon discount was wrong (agent exit code 0)" {
+		t.Fatalf("Reason = %q", got.Reason)
+	}
+	if !strings.Contains(out.String(), `a.test.md: step "apply coupon" failed: got 15%`) {
+		t.Fatalf("output = %q, want failed step", out.String())
+	}
+}
+
+func TestRunSavesAgentTranscriptNextToLog(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+
+	deps := Dependencies{
+		DiscoverTests: func(string) ([]string, error) { return []string{"a.test.md"}, nil },
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			logDir := filepath.Join(filepath.Dir(testAbs), "a.logs")
+			return logDir, filepath.Join(logDir, "20260211.log.md"), nil
+		},
+		ParseLog:    func(string) (logs.Log, error) { return logs.Log{Status: logs.StatusPass}, nil },
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         time.Now,
+		Exec: func(_ context.Context, req ExecRequest) (ExecResult, error) {
+			_, _ = io.WriteString(req.Transcript, "agent says hi\n")
+			return ExecResult{}, nil
+		},
+		Out: io.Discard,
+	}
+
+	suite, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want := filepath.Join(root, "a.logs", "20260211.transcript.txt")
+	if got := suite.Results[0].TranscriptAbs; got != want {
+		t.Fatalf("TranscriptAbs = %q, want %q", got, want)
+	}
+	content, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	if string(content) != "agent says hi\n" {
+		t.Fatalf("transcript = %q", content)
+	}
+}
@@ -0,0 +1,145 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how Run reports progress on Dependencies.Out.
+type Format string
+
+const (
+	// FormatText prints failure details and a summary line after the run.
+	FormatText Format = "text"
+	// FormatTAP streams a TAP version 13 line as each test completes.
+	FormatTAP Format = "tap"
+	// FormatJSON streams every reporter event as a line of JSON.
+	FormatJSON Format = "json"
+)
+
+type InvalidFormatError struct {
+	Raw string
+}
+
+func (e *InvalidFormatError) Error() string {
+	return fmt.Sprintf("invalid format %q (expected text, tap or json)", e.Raw)
+}
+
+func ParseFormat(raw string) (Format, error) {
+	format := Format(strings.TrimSpace(strings.ToLower(raw)))
+	switch format {
+	case "":
+		return FormatText, nil
+	case FormatText, FormatTAP, FormatJSON:
+		return format, nil
+	default:
+		return "", &InvalidFormatError{Raw: raw}
+	}
+}
+
+// tapReporter writes results as TAP. The plan comes last because the
+// number of tests is only final once the run ends.
+type tapReporter struct {
+	BaseReporter
+	w       io.Writer
+	n       int
+	started bool
+}
+
+type tapDiagnostics struct {
+	Message string `yaml:"message,omitempty"`
+	Status  string `yaml:"status"`
+	Log     string `yaml:"log,omitempty"`
+}
+
+// NewTAPReporter returns a reporter that writes TAP version 13 to w as
+// tests complete.
+func NewTAPReporter(w io.Writer) Reporter {
+	return &tapReporter{w: w}
+}
+
+func (t *tapReporter) header() {
+	if !t.started {
+		t.started = true
+		_, _ = fmt.Fprintln(t.w, "TAP version 13")
+	}
+}
+
+func (t *tapReporter) TestFinished(result TestResult) {
+	t.header()
+	t.n++
+	line := fmt.Sprintf("%s %d - %s", tapOK(result.Status), t.n, tapEscape(result.ID))
+	switch result.Status {
+	case TestPass:
+	case TestSkip:
+		line += tapDirective("SKIP", strings.TrimPrefix(result.Reason, "skip: "))
+	case TestXFail:
+		line += tapDirective("TODO", strings.TrimPrefix(result.Reason, "xfail: "))
+	default:
+		if result.Reason != "" {
+			line += " # " + tapEscape(result.Reason)
+		}
+	}
+	_, _ = fmt.Fprintln(t.w, line)
+
+	if result.Reason == "" && result.LogAbs == "" {
+		return
+	}
+	block, err := yaml.Marshal(tapDiagnostics{Message: result.Reason, Status: string(result.Status), Log: result.LogAbs})
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintln(t.w, "  ---")
+	for _, diagLine := range strings.Split(strings.TrimRight(string(block), "\n"), "\n") {
+		_, _ = fmt.Fprintln(t.w, "  "+diagLine)
+	}
+	_, _ = fmt.Fprintln(t.w, "  ...")
+}
+
+// SuiteFinished reports failed hooks and the summary as comments, then
+// the plan.
+func (t *tapReporter) SuiteFinished(suite SuiteResult) {
+	t.header()
+	for _, hook := range suite.Hooks {
+		if hook.Status != TestPass {
+			_, _ = fmt.Fprintf(t.w, "# %s %s failed: %s\n", hook.Kind, hook.HookRel, singleLine(hook.Reason))
+		}
+	}
+	_, _ = fmt.Fprintf(t.w, "# %s\n", summaryLine(suite))
+	_, _ = fmt.Fprintf(t.w, "1..%d\n", t.n)
+}
+
+// SuiteAborted bails out, which tells TAP consumers not to expect a plan.
+func (t *tapReporter) SuiteAborted(err error) {
+	t.header()
+	_, _ = fmt.Fprintf(t.w, "Bail out! %s\n", singleLine(err.Error()))
+}
+
+func tapOK(status TestStatus) string {
+	switch status {
+	case TestPass, TestSkip:
+		return "ok"
+	default:
+		return "not ok"
+	}
+}
+
+func tapDirective(name, reason string) string {
+	if reason == "" {
+		return " # " + name
+	}
+	return " # " + name + " " + tapEscape(reason)
+}
+
+// tapEscape keeps text on one line and escapes # so it cannot start a
+// directive.
+func tapEscape(text string) string {
+	return strings.ReplaceAll(singleLine(text), "#", `\#`)
+}
+
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
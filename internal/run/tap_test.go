@@ -0,0 +1,77 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunStreamsTAP(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "")
+	mustWriteFile(t, filepath.Join(root, "c.test.md"), "---\nskip: needs staging\n---\n")
+	mustWriteFile(t, filepath.Join(root, "d.test.md"), "---\nxfail: \"bug #12\"\n---\n")
+
+	var out strings.Builder
+	var streamed []string
+	deps := Dependencies{
+		DiscoverTests: func(string) ([]string, error) {
+			return []string{"a.test.md", "b.test.md", "c.test.md", "d.test.md"}, nil
+		},
+		NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+			base := strings.TrimSuffix(filepath.Base(testAbs), ".test.md")
+			logDir := filepath.Join(filepath.Dir(testAbs), base+".logs")
+			return logDir, filepath.Join(logDir, base+".log.md"), nil
+		},
+		ParseLog: func(logAbs string) (logs.Log, error) {
+			if strings.HasSuffix(logAbs, "a.log.md") {
+				return logs.Log{Status: logs.StatusPass}, nil
+			}
+			return logs.Log{Status: logs.StatusFail, Reason: "total was #3"}, nil
+		},
+		BuildPrompt: func(prompt.Input) (string, error) { return "prompt", nil },
+		MkdirAll:    os.MkdirAll,
+		Now:         time.Now,
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			// Each result must be on Out before the next test starts.
+			streamed = append(streamed, out.String())
+			return ExecResult{}, nil
+		},
+		Out: &out,
+	}
+
+	_, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, Format: FormatTAP}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(streamed) != 3 || !strings.Contains(streamed[1], "ok 1 - a.test.md\n") {
+		t.Fatalf("output before each agent run = %q, want results streamed", streamed)
+	}
+
+	aLog := filepath.Join(root, "a.logs", "a.log.md")
+	bLog := filepath.Join(root, "b.logs", "b.log.md")
+	want := "TAP version 13\n" +
+		"ok 1 - a.test.md\n" +
+		"  ---\n  status: pass\n  log: " + aLog + "\n  ...\n" +
+		"not ok 2 - b.test.md # status=fail: total was \\#3 (agent exit code 0)\n" +
+		"  ---\n  message: 'status=fail: total was #3 (agent exit code 0)'\n  status: fail\n  log: " + bLog + "\n  ...\n" +
+		"ok 3 - c.test.md # SKIP needs staging\n" +
+		"  ---\n  message: 'skip: needs staging'\n  status: skip\n  ...\n"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Fatalf("TAP output =\n%s\nwant prefix\n%s", out.String(), want)
+	}
+	if !strings.Contains(out.String(), "not ok 4 - d.test.md # TODO bug \\#12; ") {
+		t.Fatalf("TAP output = %q, want TODO for xfail", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "1..4\n") || strings.Contains(out.String(), "\nTotal:") {
+		t.Fatalf("TAP output = %q, want trailing plan and no text summary", out.String())
+	}
+}
@@ -0,0 +1,67 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// resolveVars substitutes ${NAME} placeholders in every case body. Matrix
+// parameters win over --var values, which win over mdtest.yaml vars, which
+// win over the process environment. All undefined names are reported
+// together so nothing runs with a half-rendered test.
+func resolveVars(ids []string, cases map[string]TestCase, cfg Config, deps Dependencies) error {
+	var problems []string
+	for _, id := range ids {
+		tc := cases[id]
+		configVars := tc.Config.Vars()
+		lookup := func(name string) (string, bool) {
+			for _, param := range tc.Params {
+				if param.Name == name {
+					return param.Value, true
+				}
+			}
+			if value, ok := cfg.Vars[name]; ok {
+				return value, true
+			}
+			if value, ok := configVars[name]; ok {
+				return value, true
+			}
+			return deps.LookupEnv(name)
+		}
+
+		body, vars, missing := testfile.Substitute(tc.Body, lookup)
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s", id, strings.Join(missing, ", ")))
+			continue
+		}
+		tc.Body = body
+		tc.Vars = vars
+		cases[id] = tc
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("undefined variables:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// renderedTestPath returns where the rendered copy of a test is written
+// when its body differs from the file on disk.
+func renderedTestPath(logAbs string) string {
+	return strings.TrimSuffix(logAbs, ".log.md") + ".input.md"
+}
+
+// writeRenderedTest writes tc.Body next to the log when it differs from the
+// original body and returns the path the agent should read.
+func writeRenderedTest(tc TestCase) (string, error) {
+	if tc.Body == tc.File.Body {
+		return tc.TestAbs, nil
+	}
+	renderedAbs := renderedTestPath(tc.LogAbs)
+	if err := os.WriteFile(renderedAbs, []byte(tc.Body), 0o644); err != nil {
+		return "", fmt.Errorf("write rendered test for %s: %w", tc.ID, err)
+	}
+	return renderedAbs, nil
+}
@@ -0,0 +1,119 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+func TestRunSubstitutesVarsWithPrecedence(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "mdtest.yaml"), "vars:\n  BASE_URL: http://localhost:3000\n  USER: config-user\n")
+	mustWriteFile(t, filepath.Join(root, "login.test.md"), "---\nmatrix:\n  ROLE: [admin]\n---\nOpen ${BASE_URL} as ${USER} (${ROLE}) with ${TOKEN}.\n")
+
+	var inputs []prompt.Input
+	deps := varsTestDeps(&inputs)
+	deps.LookupEnv = func(key string) (string, bool) {
+		if key == "TOKEN" || key == "USER" {
+			return "env-" + strings.ToLower(key), true
+		}
+		return "", false
+	}
+
+	result, err := Run(context.Background(), Config{
+		Root:  root,
+		Agent: agent.ClaudeAgent,
+		Vars:  map[string]string{"BASE_URL": "https://staging.example.com"},
+	}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if len(inputs) != 1 {
+		t.Fatalf("prompt inputs = %#v, want one", inputs)
+	}
+	in := inputs[0]
+	if in.SourceAbs != filepath.Join(root, "login.test.md") || !strings.HasSuffix(in.TestAbs, ".input.md") {
+		t.Fatalf("prompt input = %#v, want rendered copy of login.test.md", in)
+	}
+	rendered, err := os.ReadFile(in.TestAbs)
+	if err != nil {
+		t.Fatalf("ReadFile rendered test: %v", err)
+	}
+	want := "Open https://staging.example.com as config-user (admin) with env-token.\n"
+	if string(rendered) != want {
+		t.Fatalf("rendered test = %q, want %q", rendered, want)
+	}
+
+	wantVars := []testfile.Param{
+		{Name: "BASE_URL", Value: "https://staging.example.com"},
+		{Name: "ROLE", Value: "admin"},
+		{Name: "TOKEN", Value: "env-token"},
+		{Name: "USER", Value: "config-user"},
+	}
+	if !reflect.DeepEqual(in.Vars, wantVars) || !reflect.DeepEqual(result.Results[0].Vars, wantVars) {
+		t.Fatalf("vars = %#v / %#v, want %#v", in.Vars, result.Results[0].Vars, wantVars)
+	}
+}
+
+func TestRunReportsUndefinedVarsBeforeAgentRuns(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "Visit ${BASE_URL}.\n")
+	mustWriteFile(t, filepath.Join(root, "b.test.md"), "Plain test.\n")
+
+	var inputs []prompt.Input
+	deps := varsTestDeps(&inputs)
+	deps.LookupEnv = func(string) (string, bool) { return "", false }
+
+	_, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, deps)
+	if err == nil {
+		t.Fatal("Run returned nil error, want setup error")
+	}
+	if !strings.Contains(err.Error(), "a.test.md: BASE_URL") {
+		t.Fatalf("error = %q, want undefined BASE_URL", err)
+	}
+	if len(inputs) != 0 {
+		t.Fatalf("prompt inputs = %#v, want no agent runs", inputs)
+	}
+}
+
+func TestRunUsesOriginalPathWithoutPlaceholders(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.test.md"), "Plain test.\n")
+
+	var inputs []prompt.Input
+	if _, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, varsTestDeps(&inputs)); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if inputs[0].TestAbs != filepath.Join(root, "a.test.md") || inputs[0].SourceAbs != "" {
+		t.Fatalf("prompt input = %#v, want original path", inputs[0])
+	}
+}
+
+func varsTestDeps(inputs *[]prompt.Input) Dependencies {
+	return Dependencies{
+		DiscoverTests: DiscoverTests,
+		NextLogPath:   logs.NextLogPath,
+		ParseLog:      func(string) (logs.Log, error) { return logs.Log{Status: logs.StatusPass}, nil },
+		BuildPrompt: func(in prompt.Input) (string, error) {
+			*inputs = append(*inputs, in)
+			return "prompt", nil
+		},
+		MkdirAll: os.MkdirAll,
+		Now:      time.Now,
+		Exec: func(context.Context, ExecRequest) (ExecResult, error) {
+			return ExecResult{}, nil
+		},
+		Out: io.Discard,
+	}
+}
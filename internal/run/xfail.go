@@ -0,0 +1,30 @@
+package run
+
+import "fmt"
+
+// markedSkip returns the skip reason of a test marked skip: in its front
+// matter, unless cfg.RunXFail overrides the marker.
+func markedSkip(cfg Config, tc TestCase) string {
+	if cfg.RunXFail || tc.File.Meta.Skip == "" {
+		return ""
+	}
+	return "skip: " + tc.File.Meta.Skip
+}
+
+// applyXFail turns the verdict of a test marked xfail: into xfail or xpass.
+// Fixture errors stay errors since they say nothing about the known bug.
+func applyXFail(cfg Config, tc TestCase, result TestResult) TestResult {
+	marker := tc.File.Meta.XFail
+	if cfg.RunXFail || marker == "" {
+		return result
+	}
+	switch result.Status {
+	case TestPass:
+		result.Status = TestXPass
+		result.Reason = fmt.Sprintf("expected to fail (%s) but passed", marker)
+	case TestFail:
+		result.Status = TestXFail
+		result.Reason = fmt.Sprintf("xfail: %s; %s", marker, result.Reason)
+	}
+	return result
+}
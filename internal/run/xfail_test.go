@@ -0,0 +1,72 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+func TestRunHonorsXFailAndSkipMarkers(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "broken.test.md"), "---\nxfail: https://example.com/issues/12\n---\n")
+	mustWriteFile(t, filepath.Join(root, "fixed.test.md"), "---\nxfail: coupon bug\n---\n")
+	mustWriteFile(t, filepath.Join(root, "later.test.md"), "---\nskip: needs staging\n---\n")
+
+	newDeps := func(executed *[]string) Dependencies {
+		return Dependencies{
+			NextLogPath: func(testAbs string, _ time.Time) (string, string, error) {
+				logDir := strings.TrimSuffix(testAbs, ".test.md") + ".logs"
+				return logDir, filepath.Join(logDir, "x.log.md"), nil
+			},
+			ParseLog: func(logAbs string) (logs.Log, error) {
+				if strings.Contains(logAbs, "broken") {
+					return logs.Log{Status: logs.StatusFail}, nil
+				}
+				return logs.Log{Status: logs.StatusPass}, nil
+			},
+			BuildPrompt: func(in prompt.Input) (string, error) { return in.TestID, nil },
+			MkdirAll:    os.MkdirAll,
+			Now:         time.Now,
+			Exec: func(_ context.Context, req ExecRequest) (ExecResult, error) {
+				*executed = append(*executed, req.Argv[len(req.Argv)-1])
+				return ExecResult{}, nil
+			},
+			Out: io.Discard,
+		}
+	}
+
+	var executed []string
+	suite, err := Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent}, newDeps(&executed))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want := map[string]TestStatus{"broken.test.md": TestXFail, "fixed.test.md": TestXPass, "later.test.md": TestSkip}
+	for _, result := range suite.Results {
+		if result.Status != want[result.ID] {
+			t.Fatalf("%s status = %q, want %q (reason %q)", result.ID, result.Status, want[result.ID], result.Reason)
+		}
+	}
+	if suite.XFailed != 1 || suite.XPassed != 1 || suite.Skipped != 1 || suite.Failed != 0 {
+		t.Fatalf("counts = %+v", suite)
+	}
+	if len(executed) != 2 || suite.Results[2].Reason != "skip: needs staging" {
+		t.Fatalf("executed = %v, skip reason = %q", executed, suite.Results[2].Reason)
+	}
+
+	executed = nil
+	suite, err = Run(context.Background(), Config{Root: root, Agent: agent.ClaudeAgent, RunXFail: true}, newDeps(&executed))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(executed) != 3 || suite.Failed != 1 || suite.Passed != 2 || suite.XFailed != 0 {
+		t.Fatalf("--run-xfail executed %v with counts %+v", executed, suite)
+	}
+}
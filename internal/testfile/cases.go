@@ -0,0 +1,79 @@
+package testfile
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SectionCases is the cases value that makes every "## " heading a case.
+const SectionCases = "sections"
+
+// Cases declares that one test file holds several named cases. In front
+// matter it is either a list of case names or the string "sections".
+type Cases struct {
+	Names    []string
+	Sections bool
+}
+
+func (c *Cases) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value != SectionCases {
+			return fmt.Errorf("line %d: cases must be a list of names or %q, got %q", node.Line, SectionCases, node.Value)
+		}
+		*c = Cases{Sections: true}
+		return nil
+	}
+	var names []string
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	*c = Cases{Names: names}
+	return nil
+}
+
+// Resolve returns the case names of a file with the given body, or nil for
+// a file that is a single case.
+func (c Cases) Resolve(body string) ([]string, error) {
+	names := c.Names
+	if c.Sections {
+		names = Sections(body)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("cases: %s but the body has no ## headings", SectionCases)
+		}
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			return nil, fmt.Errorf("cases: empty case name")
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("cases: duplicate case %q", name)
+		}
+		seen[key] = true
+	}
+	return names, nil
+}
+
+// Sections returns the text of the "## " headings in body, skipping fenced
+// code blocks.
+func Sections(body string) []string {
+	var sections []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "## ") {
+			continue
+		}
+		if heading := strings.TrimSpace(strings.TrimRight(line[3:], "# \t\r")); heading != "" {
+			sections = append(sections, heading)
+		}
+	}
+	return sections
+}
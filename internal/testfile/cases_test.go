@@ -0,0 +1,49 @@
+package testfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCasesResolve(t *testing.T) {
+	body := "# Checkout\n\n## Add to cart\nClick.\n\n```md\n## not a case\n```\n\n## Apply coupon ##\nType SAVE20.\n"
+	tests := []struct {
+		name    string
+		front   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "none", front: "requires: []\n"},
+		{name: "list", front: "cases:\n  - add to cart\n  - apply coupon\n", want: []string{"add to cart", "apply coupon"}},
+		{name: "sections", front: "cases: sections\n", want: []string{"Add to cart", "Apply coupon"}},
+		{name: "duplicate", front: "cases: [a, A]\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Parse([]byte("---\n" + tt.front + "---\n" + body))
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			got, err := file.Meta.Cases.Resolve(file.Body)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Resolve returned nil error, want failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Resolve = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCasesRejectsUnknownScalar(t *testing.T) {
+	if _, err := Parse([]byte("---\ncases: headings\n---\n")); err == nil {
+		t.Fatal("Parse returned nil error, want failure")
+	}
+}
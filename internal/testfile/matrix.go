@@ -0,0 +1,121 @@
+package testfile
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Param is one matrix parameter value of an expanded test case.
+type Param struct {
+	Name  string
+	Value string
+}
+
+// MatrixAxis is one matrix key with its values.
+type MatrixAxis struct {
+	Name   string
+	Values []string
+}
+
+// Matrix keeps the axes in front matter order so case IDs are stable.
+type Matrix []MatrixAxis
+
+func (m *Matrix) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: matrix must be a mapping of parameter names to value lists", node.Line)
+	}
+	axes := make(Matrix, 0, len(node.Content)/2)
+	seen := make(map[string]bool, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		if name == "" || strings.ContainsAny(name, "[]=,/\\:%") || strings.Contains(name, "..") {
+			return fmt.Errorf("line %d: invalid matrix parameter name %q", node.Content[i].Line, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("line %d: duplicate matrix parameter %q", node.Content[i].Line, name)
+		}
+		seen[name] = true
+
+		var values StringList
+		if err := node.Content[i+1].Decode(&values); err != nil {
+			return fmt.Errorf("matrix parameter %q: %w", name, err)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("line %d: matrix parameter %q has no values", node.Content[i].Line, name)
+		}
+		axes = append(axes, MatrixAxis{Name: name, Values: values})
+	}
+	*m = axes
+	return nil
+}
+
+// Expand returns every parameter combination, varying the last axis fastest.
+// An empty matrix expands to a single case without parameters.
+func (m Matrix) Expand() [][]Param {
+	combos := [][]Param{nil}
+	for _, axis := range m {
+		next := make([][]Param, 0, len(combos)*len(axis.Values))
+		for _, combo := range combos {
+			for _, value := range axis.Values {
+				params := append(append([]Param(nil), combo...), Param{Name: axis.Name, Value: value})
+				next = append(next, params)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// CaseID returns the stable ID of an expanded case, such as
+// checkout.test.md[role=admin,locale=ja]. Without params it is testRel.
+func CaseID(testRel string, params []Param) string {
+	if len(params) == 0 {
+		return testRel
+	}
+	return testRel + "[" + formatParams(params) + "]"
+}
+
+// CaseFile returns a file path that gives an expanded case its own log
+// directory: checkout.test.md becomes checkout[role=admin].test.md. Values
+// are escaped so that, whatever they contain, the case stays one file next
+// to the test: url=http://a/b becomes url=http%3A%2F%2Fa%2Fb.
+func CaseFile(testPath string, params []Param) string {
+	if len(params) == 0 {
+		return testPath
+	}
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		parts = append(parts, param.Name+"="+escape(param.Value, "%/\\:[]=,"))
+	}
+	return strings.TrimSuffix(testPath, ".test.md") + "[" + strings.Join(parts, ",") + "].test.md"
+}
+
+// EscapePath escapes the characters of s that would split it into several
+// path elements or are not allowed in file names, keeping it one element.
+func EscapePath(s string) string {
+	return escape(s, "%/\\:")
+}
+
+// escape percent-encodes the bytes of s in special and control characters.
+func escape(s string, special string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c == 0x7f || strings.IndexByte(special, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func formatParams(params []Param) string {
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		parts = append(parts, param.Name+"="+param.Value)
+	}
+	return strings.Join(parts, ",")
+}
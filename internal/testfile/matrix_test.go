@@ -0,0 +1,80 @@
+package testfile
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatrixExpandKeepsDeclarationOrder(t *testing.T) {
+	file, err := Parse([]byte("---\nmatrix:\n  role: [admin, viewer]\n  locale: [en, ja]\n---\n"))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	var ids []string
+	for _, params := range file.Meta.Matrix.Expand() {
+		ids = append(ids, CaseID("checkout.test.md", params))
+	}
+	want := []string{
+		"checkout.test.md[role=admin,locale=en]",
+		"checkout.test.md[role=admin,locale=ja]",
+		"checkout.test.md[role=viewer,locale=en]",
+		"checkout.test.md[role=viewer,locale=ja]",
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("case IDs = %#v, want %#v", ids, want)
+	}
+}
+
+func TestMatrixExpandWithoutAxes(t *testing.T) {
+	combos := Matrix(nil).Expand()
+	if len(combos) != 1 || combos[0] != nil {
+		t.Fatalf("Expand = %#v, want one case without params", combos)
+	}
+	if got := CaseID("a.test.md", combos[0]); got != "a.test.md" {
+		t.Fatalf("CaseID = %q, want a.test.md", got)
+	}
+}
+
+func TestCaseFile(t *testing.T) {
+	got := CaseFile("/suite/checkout.test.md", []Param{{Name: "role", Value: "admin"}})
+	if got != "/suite/checkout[role=admin].test.md" {
+		t.Fatalf("CaseFile = %q", got)
+	}
+}
+
+func TestCaseFileEscapesValues(t *testing.T) {
+	tests := map[string]string{
+		"http://a/b": "/suite/checkout[url=http%3A%2F%2Fa%2Fb].test.md",
+		"../../etc":  "/suite/checkout[url=..%2F..%2Fetc].test.md",
+		`a\b`:        "/suite/checkout[url=a%5Cb].test.md",
+		"x,y=z[1]":   "/suite/checkout[url=x%2Cy%3Dz%5B1%5D].test.md",
+		"50%":        "/suite/checkout[url=50%25].test.md",
+	}
+	for value, want := range tests {
+		got := CaseFile("/suite/checkout.test.md", []Param{{Name: "url", Value: value}})
+		if got != want {
+			t.Fatalf("CaseFile(%q) = %q, want %q", value, got, want)
+		}
+		if filepath.Dir(got) != "/suite" {
+			t.Fatalf("CaseFile(%q) = %q, want a file next to the test", value, got)
+		}
+	}
+}
+
+func TestMatrixRejectsInvalidShapes(t *testing.T) {
+	tests := map[string]string{
+		"not a mapping": "---\nmatrix: [a, b]\n---\n",
+		"empty values":  "---\nmatrix:\n  role: []\n---\n",
+		"bad name":      "---\nmatrix:\n  \"a=b\": [x]\n---\n",
+		"path in name":  "---\nmatrix:\n  \"../x\": [y]\n---\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(content)); err == nil {
+				t.Fatal("Parse returned nil error, want failure")
+			}
+		})
+	}
+}
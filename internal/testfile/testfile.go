@@ -0,0 +1,99 @@
+package testfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Meta is the optional YAML front matter of a .test.md file.
+type Meta struct {
+	Requires    []string   `yaml:"requires"`
+	SideEffects bool       `yaml:"side-effects"`
+	Before      StringList `yaml:"before"`
+	After       StringList `yaml:"after"`
+	// DependsOnTests lists suite-relative tests that must pass first.
+	DependsOnTests []string `yaml:"depends-on-tests"`
+	// Matrix expands the file into one case per parameter combination.
+	Matrix Matrix `yaml:"matrix"`
+	// Cases splits the file into named cases reported separately in the log.
+	Cases Cases `yaml:"cases"`
+	// XFail marks a known failure, with a reason or issue link.
+	XFail string `yaml:"xfail"`
+	// Skip keeps the test from running, with a reason.
+	Skip string `yaml:"skip"`
+}
+
+// File is a parsed test file. Body excludes the front matter block.
+type File struct {
+	Meta Meta
+	// FrontMatter holds every front matter key, including ones Meta ignores.
+	FrontMatter map[string]any
+	Body        string
+	// BodyOffset is the number of lines before Body, i.e. those of the
+	// front matter block, for reporting line numbers in the file.
+	BodyOffset int
+}
+
+// StringList accepts either a single string or a list of strings.
+type StringList []string
+
+func (c *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = StringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+func Read(path string) (File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("read test: %w", err)
+	}
+	return Parse(content)
+}
+
+// Parse splits optional front matter from the body. Front matter is only
+// recognized when the content starts with a --- line.
+func Parse(content []byte) (File, error) {
+	first, rest, _ := bytes.Cut(content, []byte("\n"))
+	if strings.TrimSuffix(string(first), "\r") != "---" {
+		return File{Body: string(content)}, nil
+	}
+
+	yamlLines := make([]string, 0)
+	closed := false
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		text := strings.TrimSuffix(string(line), "\r")
+		if text == "---" {
+			closed = true
+			break
+		}
+		yamlLines = append(yamlLines, text)
+	}
+	if !closed {
+		return File{}, fmt.Errorf("missing closing front matter delimiter")
+	}
+
+	text := []byte(strings.Join(yamlLines, "\n"))
+	var meta Meta
+	if err := yaml.Unmarshal(text, &meta); err != nil {
+		return File{}, fmt.Errorf("parse front matter: %w", err)
+	}
+	raw := make(map[string]any)
+	if err := yaml.Unmarshal(text, &raw); err != nil {
+		return File{}, fmt.Errorf("parse front matter: %w", err)
+	}
+	return File{Meta: meta, FrontMatter: raw, Body: string(rest), BodyOffset: len(yamlLines) + 2}, nil
+}
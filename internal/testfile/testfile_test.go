@@ -0,0 +1,74 @@
+package testfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWithoutFrontMatter(t *testing.T) {
+	got, err := Parse([]byte("# Title\n\n1. Step\n"))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got.Body != "# Title\n\n1. Step\n" {
+		t.Fatalf("Body = %q, want whole content", got.Body)
+	}
+	if !reflect.DeepEqual(got.Meta, Meta{}) {
+		t.Fatalf("Meta = %#v, want zero value", got.Meta)
+	}
+}
+
+func TestParseFrontMatterFields(t *testing.T) {
+	content := "---\nrequires: [browser]\nside-effects: true\nbefore: docker compose up -d\nafter:\n  - docker compose down\n  - rm -f app.db\n---\n# Title\n"
+
+	got, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	want := Meta{
+		Requires:    []string{"browser"},
+		SideEffects: true,
+		Before:      StringList{"docker compose up -d"},
+		After:       StringList{"docker compose down", "rm -f app.db"},
+	}
+	if !reflect.DeepEqual(got.Meta, want) {
+		t.Fatalf("Meta = %#v, want %#v", got.Meta, want)
+	}
+	if got.Body != "# Title\n" {
+		t.Fatalf("Body = %q, want %q", got.Body, "# Title\n")
+	}
+	if got.BodyOffset != 8 {
+		t.Fatalf("BodyOffset = %d, want 8", got.BodyOffset)
+	}
+	if got.FrontMatter["side-effects"] != true {
+		t.Fatalf("FrontMatter = %#v, want raw side-effects key", got.FrontMatter)
+	}
+}
+
+func TestParseFailureCases(t *testing.T) {
+	tests := map[string]string{
+		"missing closing delimiter": "---\nbefore: x\n",
+		"malformed yaml":            "---\nbefore: [\n---\n",
+		"wrong type":                "---\nbefore: {a: b}\n---\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(content)); err == nil {
+				t.Fatal("Parse returned nil error, want failure")
+			}
+		})
+	}
+}
+
+func TestParseCRLFFrontMatter(t *testing.T) {
+	got, err := Parse([]byte("---\r\nbefore: make seed\r\n---\r\n# Title\r\n"))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got.Body != "# Title\r\n" {
+		t.Fatalf("Body = %q, want %q", got.Body, "# Title\r\n")
+	}
+	if !reflect.DeepEqual(got.Meta.Before, StringList{"make seed"}) {
+		t.Fatalf("Before = %#v, want [make seed]", got.Meta.Before)
+	}
+}
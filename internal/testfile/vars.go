@@ -0,0 +1,44 @@
+package testfile
+
+import (
+	"regexp"
+	"sort"
+)
+
+var placeholderPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Substitute replaces ${NAME} placeholders in body with values from lookup.
+// $${NAME} is kept as a literal ${NAME}. It returns the rendered body, the
+// referenced variables sorted by name, and the sorted names lookup could not
+// resolve.
+func Substitute(body string, lookup func(name string) (string, bool)) (string, []Param, []string) {
+	used := make(map[string]string)
+	missing := make(map[string]bool)
+
+	rendered := placeholderPattern.ReplaceAllStringFunc(body, func(match string) string {
+		if match[1] == '$' {
+			return match[1:]
+		}
+		name := match[2 : len(match)-1]
+		value, ok := lookup(name)
+		if !ok {
+			missing[name] = true
+			return match
+		}
+		used[name] = value
+		return value
+	})
+
+	vars := make([]Param, 0, len(used))
+	for name, value := range used {
+		vars = append(vars, Param{Name: name, Value: value})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return rendered, vars, names
+}
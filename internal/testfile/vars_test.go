@@ -0,0 +1,28 @@
+package testfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubstitute(t *testing.T) {
+	values := map[string]string{"BASE_URL": "https://staging.example.com", "USER": "qa"}
+	lookup := func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+
+	got, vars, missing := Substitute("Open ${BASE_URL}/login as ${USER}, then ${TOKEN} and $${BASE_URL} and $HOME.", lookup)
+
+	wantBody := "Open https://staging.example.com/login as qa, then ${TOKEN} and ${BASE_URL} and $HOME."
+	if got != wantBody {
+		t.Fatalf("body = %q, want %q", got, wantBody)
+	}
+	wantVars := []Param{{Name: "BASE_URL", Value: "https://staging.example.com"}, {Name: "USER", Value: "qa"}}
+	if !reflect.DeepEqual(vars, wantVars) {
+		t.Fatalf("vars = %#v, want %#v", vars, wantVars)
+	}
+	if !reflect.DeepEqual(missing, []string{"TOKEN"}) {
+		t.Fatalf("missing = %#v, want [TOKEN]", missing)
+	}
+}
@@ -0,0 +1,56 @@
+package mdtest
+
+import (
+	"io"
+
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+// Reporter observes a run through Dependencies.Reporters. Embed
+// BaseReporter so that events added in later versions do not break your
+// implementation.
+type Reporter = run.Reporter
+
+// BaseReporter implements every Reporter method as a no-op.
+type BaseReporter = run.BaseReporter
+
+// Events passed to a Reporter, in the order Run sends them.
+type (
+	SuiteStart    = run.SuiteStart
+	ScheduledTest = run.ScheduledTest
+	TestStart     = run.TestStart
+	AgentStart    = run.AgentStart
+	AgentExit     = run.AgentExit
+	LogParsed     = run.LogParsed
+)
+
+// ConsoleMode selects how the console reporter shows a run.
+type ConsoleMode = run.ConsoleMode
+
+const (
+	ConsoleProgress = run.ConsoleProgress
+	ConsolePlain    = run.ConsolePlain
+)
+
+// NewSummaryReporter returns the reporter for FormatText: failure details
+// and a summary line after the run.
+func NewSummaryReporter(out io.Writer) Reporter {
+	return run.NewSummaryReporter(out)
+}
+
+// NewConsoleReporter returns a reporter that prints each test as it
+// completes and agent output prefixed with the test ID, then the summary.
+func NewConsoleReporter(out io.Writer, mode ConsoleMode) Reporter {
+	return run.NewConsoleReporter(out, mode)
+}
+
+// NewTAPReporter returns the reporter for FormatTAP.
+func NewTAPReporter(w io.Writer) Reporter {
+	return run.NewTAPReporter(w)
+}
+
+// NewJSONReporter returns the reporter for FormatJSON, which writes one JSON
+// object per event.
+func NewJSONReporter(w io.Writer) Reporter {
+	return run.NewJSONReporter(w)
+}
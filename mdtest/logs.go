@@ -0,0 +1,52 @@
+package mdtest
+
+import (
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+)
+
+// Log is the front matter of a log written by the agent.
+type (
+	Log       = logs.Log
+	LogStatus = logs.Status
+	LogStep   = logs.Step
+)
+
+const (
+	LogPass = logs.StatusPass
+	LogFail = logs.StatusFail
+)
+
+// LogDiagnostic is one way a log breaks the log schema.
+type LogDiagnostic = logs.Diagnostic
+
+// LogState is the TestResult.LogState of a test: whether the agent left a
+// usable log.
+type LogState = run.LogState
+
+const (
+	LogOK      = run.LogOK
+	LogMissing = run.LogMissing
+	LogInvalid = run.LogInvalid
+)
+
+// InvalidLogError is the LogParsed.Err of a log rejected by Config.StrictLog.
+type InvalidLogError = run.InvalidLogError
+
+// ParseLog reads the front matter of the log at path. It fails when the
+// status is missing or invalid and ignores malformed optional fields.
+func ParseLog(path string) (Log, error) {
+	return logs.ParseLog(path)
+}
+
+// ValidateLog checks the log at path strictly against the log schema, as
+// --strict-log and mdtest validate-log do. The error is only for logs that
+// cannot be read.
+func ValidateLog(path string) ([]LogDiagnostic, error) {
+	return logs.Validate(path)
+}
+
+// ValidateLogContent is ValidateLog for a log already in memory.
+func ValidateLogContent(content []byte) []LogDiagnostic {
+	return logs.ValidateContent(content)
+}
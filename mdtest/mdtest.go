@@ -0,0 +1,280 @@
+// Package mdtest runs suites of Markdown tests with a coding agent. It is
+// the supported API for embedding the runner in other tools; the mdtest
+// command is built on it.
+//
+// Stability: exported identifiers of this package follow semantic
+// versioning. Within a major version they are not removed or renamed, and
+// struct types only gain fields, so use keyed composite literals. Most
+// types are aliases of the implementation's types, which may gain methods
+// but keep their meaning. Every type used by an exported field, function or
+// method has a name in this package, so no internal package is needed to
+// implement a Dependencies function.
+package mdtest
+
+import (
+	"context"
+	"io"
+	"os/exec"
+
+	"github.com/PeronGH/mdtest-cli/internal/agent"
+	"github.com/PeronGH/mdtest-cli/internal/fakeagent"
+	"github.com/PeronGH/mdtest-cli/internal/procexec"
+	"github.com/PeronGH/mdtest-cli/internal/run"
+	"github.com/PeronGH/mdtest-cli/internal/testfile"
+)
+
+// Config selects the tests to run and how to run them. Root and Agent are
+// required.
+type Config = run.Config
+
+// Dependencies are the functions Run uses to reach the outside world. Nil
+// fields fall back to the defaults, so tests only need to set the ones
+// they replace.
+type Dependencies = run.Dependencies
+
+// ExecFunc runs one agent invocation.
+type (
+	ExecFunc    = run.ExecFunc
+	ExecRequest = run.ExecRequest
+	ExecResult  = run.ExecResult
+)
+
+// ShellFunc runs one before: or after: shell fixture.
+type (
+	ShellFunc    = run.ShellFunc
+	ShellRequest = run.ShellRequest
+)
+
+// SetupError is returned by Run when the suite could not start, e.g.
+// because no tests were found or the agent could not be executed.
+type SetupError = run.SetupError
+
+// SuiteResult holds the outcome of every test and hook of a run.
+type (
+	SuiteResult = run.SuiteResult
+	TestResult  = run.TestResult
+	CaseResult  = run.CaseResult
+	HookResult  = run.HookResult
+	HookKind    = run.HookKind
+	TestCase    = run.TestCase
+	TestStatus  = run.TestStatus
+)
+
+const (
+	TestPass  = run.TestPass
+	TestFail  = run.TestFail
+	TestSkip  = run.TestSkip
+	TestError = run.TestError
+	TestXFail = run.TestXFail
+	TestXPass = run.TestXPass
+
+	HookSetup    = run.HookSetup
+	HookTeardown = run.HookTeardown
+)
+
+// Agent names a supported coding agent CLI.
+type Agent = agent.Name
+
+const (
+	Claude = agent.ClaudeAgent
+	Codex  = agent.CodexAgent
+	// Fake is the scripted fake agent; run it with FakeExec.
+	Fake = agent.FakeAgent
+)
+
+// AgentMode is the --agent flag: a specific agent, or auto to use the first
+// one installed.
+type AgentMode = agent.Mode
+
+const (
+	AgentAuto   = agent.AutoMode
+	AgentClaude = agent.ClaudeMode
+	AgentCodex  = agent.CodexMode
+	AgentFake   = agent.FakeMode
+)
+
+// LookPathFunc finds an executable, like exec.LookPath.
+type LookPathFunc = agent.LookPathFunc
+
+// Order is the order tests run in.
+type Order = run.Order
+
+const (
+	OrderLexical      = run.OrderLexical
+	OrderRandom       = run.OrderRandom
+	OrderSlowestFirst = run.OrderSlowestFirst
+	OrderFailedFirst  = run.OrderFailedFirst
+)
+
+// PromptMode selects whether the agent is given the test's path or its
+// body.
+type PromptMode = run.PromptMode
+
+const (
+	PromptModePath   = run.PromptModePath
+	PromptModeInline = run.PromptModeInline
+)
+
+// Format selects what Run prints to Dependencies.Out when no reporter is
+// registered.
+type Format = run.Format
+
+const (
+	FormatText = run.FormatText
+	FormatTAP  = run.FormatTAP
+	FormatJSON = run.FormatJSON
+)
+
+// Errors returned by the Parse functions for unknown values.
+type (
+	InvalidAgentModeError  = agent.InvalidModeError
+	AgentNotFoundError     = agent.NotFoundError
+	InvalidOrderError      = run.InvalidOrderError
+	InvalidPromptModeError = run.InvalidPromptModeError
+	InvalidFormatError     = run.InvalidFormatError
+)
+
+// Run runs the suite selected by cfg. The error is a *SetupError when the
+// suite could not run; test failures are reported in the result.
+func Run(ctx context.Context, cfg Config, deps Dependencies) (SuiteResult, error) {
+	return run.Run(ctx, cfg, deps)
+}
+
+// DefaultDependencies returns dependencies that run the agent and shell
+// fixtures as child processes of the current one, with results printed to
+// out. The agent's output goes to the terminal; see ProcessExec to send it
+// elsewhere.
+func DefaultDependencies(out io.Writer) Dependencies {
+	return run.DefaultDependencies(out, ProcessExec(nil))
+}
+
+// ProcessExec returns an ExecFunc that runs the agent as a child process.
+// Its output goes to ExecRequest.Output when a reporter asks for it, else
+// to agentOut, else to the terminal.
+func ProcessExec(agentOut io.Writer) ExecFunc {
+	return func(ctx context.Context, req ExecRequest) (ExecResult, error) {
+		stdout, stderr := req.Output, req.Output
+		if stdout == nil && agentOut != nil {
+			stdout, stderr = agentOut, agentOut
+		}
+		result, err := procexec.Run(ctx, procexec.Request{
+			RootAbs:     req.RootAbs,
+			Argv:        req.Argv,
+			Interactive: req.Interactive,
+			Transcript:  req.Transcript,
+			Stdout:      stdout,
+			Stderr:      stderr,
+		})
+		return ExecResult{ExitCode: result.ExitCode}, err
+	}
+}
+
+// ProcessShell runs a shell fixture with sh -c in the suite root.
+func ProcessShell(ctx context.Context, req ShellRequest) (ExecResult, error) {
+	return run.ProcessShell(ctx, req)
+}
+
+// FakeExec runs the fake agent, which writes the log scripted by the
+// test's <name>.fake.yaml file or fake: front matter instead of calling a
+// model. Use it as Dependencies.Exec with Config.Agent set to Fake.
+func FakeExec(ctx context.Context, req ExecRequest) (ExecResult, error) {
+	return fakeagent.Exec(ctx, req)
+}
+
+// Cassette is the recording of one agent invocation, saved by RecordExec or
+// Config.RecordDir.
+type Cassette = run.Cassette
+
+// MissingCassetteError is returned when a replay reaches an invocation that
+// was never recorded.
+type MissingCassetteError = run.MissingCassetteError
+
+// CassetteMismatchError is returned when a replayed invocation's prompt or
+// argv differ from the recording.
+type CassetteMismatchError = run.CassetteMismatchError
+
+// CassettePath returns the file in dir that records attempt of the test or
+// hook id.
+func CassettePath(dir string, id string, attempt int) string {
+	return run.CassettePath(dir, id, attempt)
+}
+
+// RecordExec wraps exec to save each invocation to a cassette in dir.
+func RecordExec(dir string, exec ExecFunc) ExecFunc {
+	return run.RecordExec(dir, exec)
+}
+
+// ReplayExec returns an ExecFunc that plays back the cassettes in dir
+// instead of running an agent.
+func ReplayExec(dir string) ExecFunc {
+	return run.ReplayExec(dir)
+}
+
+// DefaultLookPath finds agent binaries on PATH.
+func DefaultLookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// ParseAgentMode parses the --agent flag.
+func ParseAgentMode(raw string) (AgentMode, error) {
+	return agent.ParseMode(raw)
+}
+
+// ResolveAgent picks the agent for mode, checking that its binary is
+// installed. The error is an *AgentNotFoundError when it is not.
+func ResolveAgent(mode AgentMode, lookPath LookPathFunc) (Agent, error) {
+	return agent.Resolve(mode, lookPath)
+}
+
+// ParseOrder parses the --order flag; empty means lexical.
+func ParseOrder(raw string) (Order, error) {
+	return run.ParseOrder(raw)
+}
+
+// ParsePromptMode parses the --prompt-mode flag; empty means path.
+func ParsePromptMode(raw string) (PromptMode, error) {
+	return run.ParsePromptMode(raw)
+}
+
+// ParseFormat parses the --format flag; empty means text.
+func ParseFormat(raw string) (Format, error) {
+	return run.ParseFormat(raw)
+}
+
+// PromptPreview is the prompt Run would send for one test case.
+type PromptPreview = run.PromptPreview
+
+// PreviewPrompts renders the prompts Run would send for the tests selected
+// by cfg without running anything.
+func PreviewPrompts(cfg Config, deps Dependencies) ([]PromptPreview, error) {
+	return run.PreviewPrompts(cfg, deps)
+}
+
+// TestFile is a parsed .test.md file.
+type (
+	TestFile   = testfile.File
+	TestMeta   = testfile.Meta
+	Param      = testfile.Param
+	Matrix     = testfile.Matrix
+	MatrixAxis = testfile.MatrixAxis
+	Cases      = testfile.Cases
+	StringList = testfile.StringList
+)
+
+// ReadTestFile reads and parses the .test.md file at path.
+func ReadTestFile(path string) (TestFile, error) {
+	return testfile.Read(path)
+}
+
+// DiscoverTests returns the .test.md files under root as sorted,
+// slash-separated paths relative to root.
+func DiscoverTests(root string) ([]string, error) {
+	return run.DiscoverTests(root)
+}
+
+// ResolveExplicitTests turns test paths, relative to rootAbs or absolute,
+// into sorted paths relative to rootAbs. Every path must be a .test.md file
+// inside rootAbs.
+func ResolveExplicitTests(rootAbs string, files []string) ([]string, error) {
+	return run.ResolveExplicitTests(rootAbs, files)
+}
@@ -0,0 +1,252 @@
+package mdtest_test
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/mdtest"
+)
+
+// logWriter is a reporter that remembers where the running test's log goes,
+// so a fake agent can write it.
+type logWriter struct {
+	mdtest.BaseReporter
+	logAbs   string
+	finished []mdtest.TestStatus
+}
+
+func (w *logWriter) TestStarted(test mdtest.TestStart) {
+	w.logAbs = test.LogAbs
+}
+
+func (w *logWriter) TestFinished(result mdtest.TestResult) {
+	w.finished = append(w.finished, result.Status)
+}
+
+func TestRunThroughPublicAPI(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"a.test.md":     "# A\n",
+		"sub/b.test.md": "# B\n",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids, err := mdtest.DiscoverTests(root)
+	if err != nil {
+		t.Fatalf("DiscoverTests returned error: %v", err)
+	}
+	if want := []string{"a.test.md", "sub/b.test.md"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("DiscoverTests = %#v, want %#v", ids, want)
+	}
+
+	reporter := &logWriter{}
+	statuses := []string{"pass", "fail"}
+	deps := mdtest.DefaultDependencies(io.Discard)
+	deps.Reporters = []mdtest.Reporter{reporter}
+	deps.Exec = func(_ context.Context, req mdtest.ExecRequest) (mdtest.ExecResult, error) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		content := "---\nstatus: " + status + "\nreason: scripted\n---\n\nDid it.\n"
+		return mdtest.ExecResult{}, os.WriteFile(reporter.logAbs, []byte(content), 0o644)
+	}
+
+	suite, err := mdtest.Run(context.Background(), mdtest.Config{Root: root, Agent: mdtest.Claude}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if suite.Total != 2 || suite.Passed != 1 || suite.Failed != 1 {
+		t.Fatalf("suite counts = %d/%d/%d, want 2/1/1", suite.Total, suite.Passed, suite.Failed)
+	}
+	if want := []mdtest.TestStatus{mdtest.TestPass, mdtest.TestFail}; !reflect.DeepEqual(reporter.finished, want) {
+		t.Fatalf("finished = %#v, want %#v", reporter.finished, want)
+	}
+
+	log, err := mdtest.ParseLog(suite.Results[1].LogAbs)
+	if err != nil {
+		t.Fatalf("ParseLog returned error: %v", err)
+	}
+	if log.Status != mdtest.LogFail || log.Reason != "scripted" {
+		t.Fatalf("log = %#v, want fail with reason", log)
+	}
+	diags, err := mdtest.ValidateLog(suite.Results[1].LogAbs)
+	if err != nil || len(diags) != 0 {
+		t.Fatalf("ValidateLog = %#v, %v, want no diagnostics", diags, err)
+	}
+}
+
+func TestRunReturnsSetupErrorForEmptySuite(t *testing.T) {
+	_, err := mdtest.Run(context.Background(), mdtest.Config{Root: t.TempDir(), Agent: mdtest.Codex}, mdtest.DefaultDependencies(io.Discard))
+	var setupErr *mdtest.SetupError
+	if !errors.As(err, &setupErr) {
+		t.Fatalf("Run error = %v, want *SetupError", err)
+	}
+}
+
+func TestDependenciesCanBeWrappedWithPublicTypes(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.test.md"), []byte("# A\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var inputs []mdtest.PromptInput
+	var stacks []mdtest.ConfigStack
+	deps := mdtest.DefaultDependencies(io.Discard)
+	deps.Exec = mdtest.FakeExec
+	deps.LoadConfig = func(rootAbs string, dirRel string) (mdtest.ConfigStack, error) {
+		stack, err := mdtest.LoadConfig(rootAbs, dirRel)
+		stacks = append(stacks, stack)
+		return stack, err
+	}
+	deps.LatestRun = func(testAbs string) (mdtest.PreviousRun, bool, error) {
+		return mdtest.PreviousRun{Status: mdtest.LogFail, Reason: "flaky"}, true, nil
+	}
+	deps.BuildPrompt = func(in mdtest.PromptInput) (string, error) {
+		inputs = append(inputs, in)
+		return mdtest.BuildPrompt(in)
+	}
+
+	suite, err := mdtest.Run(context.Background(), mdtest.Config{Root: root, Agent: mdtest.Fake}, deps)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if suite.Passed != 1 {
+		t.Fatalf("suite = %#v, want one pass", suite)
+	}
+	if len(stacks) == 0 || len(inputs) != 1 || inputs[0].PreviousFailure != "status=fail: flaky" {
+		t.Fatalf("inputs = %#v, stacks = %#v", inputs, stacks)
+	}
+}
+
+// TestEveryReachableTypeHasAPublicName walks the types reachable from the
+// exported API and fails for any type of an internal package that this
+// package does not alias.
+func TestEveryReachableTypeHasAPublicName(t *testing.T) {
+	aliased := publicAliases(t)
+	roots := []any{
+		mdtest.Run, mdtest.DefaultDependencies, mdtest.ProcessExec, mdtest.ProcessShell,
+		mdtest.FakeExec, mdtest.CassettePath, mdtest.RecordExec, mdtest.ReplayExec,
+		mdtest.DefaultLookPath, mdtest.ParseAgentMode, mdtest.ResolveAgent, mdtest.ParseOrder,
+		mdtest.ParsePromptMode, mdtest.ParseFormat, mdtest.PreviewPrompts, mdtest.ReadTestFile,
+		mdtest.DiscoverTests, mdtest.ResolveExplicitTests, mdtest.ParseLog, mdtest.ValidateLog,
+		mdtest.ValidateLogContent, mdtest.BuildPrompt, mdtest.RenderPromptFile,
+		mdtest.BuildRepairPrompt, mdtest.LoadConfig, mdtest.LatestRun, mdtest.NextLogPath,
+		mdtest.ParseReportSpec, mdtest.WriteReports, mdtest.NewFileReporter,
+		mdtest.LookupGitHubEnv, mdtest.NewGitHubReporter, mdtest.NewSummaryReporter,
+		mdtest.NewConsoleReporter, mdtest.NewTAPReporter, mdtest.NewJSONReporter,
+		(*mdtest.Reporter)(nil), (*mdtest.BaseReporter)(nil), (*mdtest.SetupError)(nil),
+		(*mdtest.Cassette)(nil), (*mdtest.MissingCassetteError)(nil),
+		(*mdtest.CassetteMismatchError)(nil), (*mdtest.InvalidLogError)(nil),
+		(*mdtest.InvalidAgentModeError)(nil), (*mdtest.AgentNotFoundError)(nil),
+		(*mdtest.InvalidOrderError)(nil), (*mdtest.InvalidPromptModeError)(nil),
+		(*mdtest.InvalidFormatError)(nil), (*mdtest.InvalidReportSpecError)(nil),
+	}
+
+	seen := map[reflect.Type]bool{}
+	var walk func(typ reflect.Type, path string)
+	walk = func(typ reflect.Type, path string) {
+		if seen[typ] {
+			return
+		}
+		seen[typ] = true
+		if typ.Name() != "" && strings.Contains(typ.PkgPath(), "/internal/") {
+			if !aliased[typ.PkgPath()+"."+typ.Name()] {
+				t.Errorf("%s: %s has no name in package mdtest", path, typ)
+			}
+		}
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Chan:
+			walk(typ.Elem(), path)
+		case reflect.Map:
+			walk(typ.Key(), path)
+			walk(typ.Elem(), path)
+		case reflect.Struct:
+			for i := range typ.NumField() {
+				if field := typ.Field(i); field.IsExported() {
+					walk(field.Type, path+"."+field.Name)
+				}
+			}
+		case reflect.Func:
+			for i := range typ.NumIn() {
+				walk(typ.In(i), path)
+			}
+			for i := range typ.NumOut() {
+				walk(typ.Out(i), path)
+			}
+		}
+		if typ.Kind() != reflect.Interface && typ.Kind() != reflect.Pointer {
+			typ = reflect.PointerTo(typ)
+		}
+		for i := range typ.NumMethod() {
+			method := typ.Method(i)
+			walk(method.Type, path+"."+method.Name)
+		}
+	}
+	for _, root := range roots {
+		typ := reflect.TypeOf(root)
+		name := typ.String()
+		if typ.Kind() == reflect.Func {
+			name = runtime.FuncForPC(reflect.ValueOf(root).Pointer()).Name()
+		}
+		walk(typ, name)
+	}
+}
+
+// publicAliases returns the import path and name of every type this package
+// declares as an alias, such as "…/internal/run.Config".
+func publicAliases(t *testing.T) map[string]bool {
+	t.Helper()
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliased := map[string]bool{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		imports := map[string]string{}
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			name := importPath[strings.LastIndex(importPath, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = importPath
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok || !spec.Assign.IsValid() {
+				return true
+			}
+			if sel, ok := spec.Type.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok {
+					aliased[imports[pkg.Name]+"."+sel.Sel.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return aliased
+}
@@ -0,0 +1,64 @@
+package mdtest
+
+import (
+	"time"
+
+	"github.com/PeronGH/mdtest-cli/internal/config"
+	"github.com/PeronGH/mdtest-cli/internal/logs"
+	"github.com/PeronGH/mdtest-cli/internal/prompt"
+)
+
+// PromptInput is the data model of prompt templates, passed to
+// Dependencies.BuildPrompt and Dependencies.RenderPromptFile.
+type (
+	PromptInput       = prompt.Input
+	RepairPromptInput = prompt.RepairInput
+	ContextFile       = prompt.ContextFile
+)
+
+// DirConfig is the content of one mdtest.yaml; ConfigStack holds those from
+// the suite root down to a test's directory, as Dependencies.LoadConfig
+// returns them.
+type (
+	DirConfig   = config.Dir
+	ConfigStack = config.Stack
+)
+
+// PreviousRun is the newest earlier log of a test, as
+// Dependencies.LatestRun returns it.
+type PreviousRun = logs.PreviousRun
+
+// The functions below are the defaults DefaultDependencies uses, for
+// Dependencies fields that wrap rather than replace them.
+
+// BuildPrompt renders the built-in agent prompt.
+func BuildPrompt(in PromptInput) (string, error) {
+	return prompt.Render(in)
+}
+
+// RenderPromptFile renders the prompt template at path.
+func RenderPromptFile(path string, in PromptInput) (string, error) {
+	return prompt.RenderFile(path, in)
+}
+
+// BuildRepairPrompt renders the prompt asking the agent to fix its log.
+func BuildRepairPrompt(in RepairPromptInput) (string, error) {
+	return prompt.RenderRepair(in)
+}
+
+// LoadConfig reads the mdtest.yaml files from rootAbs down to dirRel.
+func LoadConfig(rootAbs string, dirRel string) (ConfigStack, error) {
+	return config.Load(rootAbs, dirRel)
+}
+
+// LatestRun returns the newest log of the test at testAbs; the boolean is
+// false when it has none.
+func LatestRun(testAbs string) (PreviousRun, bool, error) {
+	return logs.LatestRun(testAbs)
+}
+
+// NextLogPath returns the log directory of the test at testAbs and a new log
+// path in it for a run starting at.
+func NextLogPath(testAbs string, at time.Time) (string, string, error) {
+	return logs.NextLogPath(testAbs, at)
+}
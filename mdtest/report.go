@@ -0,0 +1,52 @@
+package mdtest
+
+import (
+	"io"
+
+	"github.com/PeronGH/mdtest-cli/internal/report"
+)
+
+// ReportSpec is one --report format=path flag.
+type (
+	ReportSpec             = report.Spec
+	ReportFormat           = report.Format
+	InvalidReportSpecError = report.InvalidSpecError
+	FileReporter           = report.FileReporter
+	GitHubEnv              = report.GitHubEnv
+	GitHubReporter         = report.GitHubReporter
+)
+
+const (
+	ReportJUnit    = report.FormatJUnit
+	ReportMarkdown = report.FormatMarkdown
+	ReportHTML     = report.FormatHTML
+)
+
+// ParseReportSpec parses a --report flag such as "junit=results.xml".
+func ParseReportSpec(raw string) (ReportSpec, error) {
+	return report.ParseSpec(raw)
+}
+
+// WriteReports writes suite in the format of every spec.
+func WriteReports(specs []ReportSpec, suite SuiteResult) error {
+	return report.Write(specs, suite)
+}
+
+// NewFileReporter returns a reporter that writes specs when the suite
+// finishes. Check its Err after Run returns.
+func NewFileReporter(specs []ReportSpec) *FileReporter {
+	return report.NewFileReporter(specs)
+}
+
+// LookupGitHubEnv returns the GitHub Actions environment, and false outside
+// GitHub Actions.
+func LookupGitHubEnv(lookupEnv func(key string) (string, bool)) (GitHubEnv, bool) {
+	return report.LookupGitHubEnv(lookupEnv)
+}
+
+// NewGitHubReporter returns a reporter that writes error annotations to w
+// and the job summary when the suite finishes. Check its Err after Run
+// returns.
+func NewGitHubReporter(w io.Writer, env GitHubEnv) *GitHubReporter {
+	return report.NewGitHubReporter(w, env)
+}
@@ -0,0 +1,220 @@
+// Package mdtesting runs Markdown tests from go test:
+//
+//	func TestMarkdown(t *testing.T) {
+//		mdtesting.Run(t, "testdata/*.test.md", mdtesting.Options{})
+//	}
+//
+// Every matching file becomes a subtest, so -run selects files as it does
+// subtests, e.g. -run 'TestMarkdown/testdata/login'. Tests that need a real
+// agent are skipped with -short.
+package mdtesting
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/mdtest"
+)
+
+// Options configure Run. The zero value runs each test with the first agent
+// installed, in the package directory.
+type Options struct {
+	// Root is the suite root the agent works in and the glob is relative to.
+	// Empty means the package directory.
+	Root string
+	// Agent selects the agent; empty picks claude or codex, whichever is
+	// installed, and skips the tests when neither is. mdtest.Fake runs the
+	// scripted fake agent, also with -short.
+	Agent mdtest.Agent
+	// Vars are values for ${NAME} placeholders, as with --var.
+	Vars                       map[string]string
+	PromptMode                 mdtest.PromptMode
+	StrictLog                  bool
+	RunXFail                   bool
+	RepairLimit                int
+	DangerouslyAllowAllActions bool
+	// Exec replaces the agent, e.g. with a fake that writes the log at
+	// ExecRequest.LogAbs. Tests with Exec set also run with -short.
+	Exec mdtest.ExecFunc
+}
+
+// Run runs every .test.md file matching pattern, a filepath.Glob pattern
+// relative to opts.Root, as a subtest of t named after the file's path
+// without the .test.md suffix. Failed and errored tests fail their
+// subtest, skipped ones skip it, and the log path is attached with t.Log.
+// Setup and teardown hooks run once per file.
+func Run(t *testing.T, pattern string, opts Options) {
+	t.Helper()
+	root := opts.Root
+	if root == "" {
+		root = "."
+	}
+	matches, err := filepath.Glob(filepath.Join(root, pattern))
+	if err != nil {
+		t.Fatalf("mdtesting: %v", err)
+	}
+	var files []string
+	for _, match := range matches {
+		if strings.HasSuffix(match, ".test.md") {
+			files = append(files, match)
+		}
+	}
+	if len(files) == 0 {
+		t.Fatalf("mdtesting: no .test.md files match %q in %s", pattern, root)
+	}
+
+	for _, file := range files {
+		// Run resolves test paths against the root, so pass the path
+		// relative to it rather than the glob match, which includes it.
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			t.Fatalf("mdtesting: %v", err)
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), ".test.md")
+		t.Run(name, func(t *testing.T) {
+			runFile(t, root, rel, opts)
+		})
+	}
+}
+
+// runFile runs the test at rel, relative to root.
+func runFile(t *testing.T, root, rel string, opts Options) {
+	agentName := opts.Agent
+	if agentName == mdtest.Fake && opts.Exec == nil {
+		opts.Exec = mdtest.FakeExec
+	}
+	if opts.Exec == nil {
+		if testing.Short() {
+			t.Skip("agent-backed Markdown test skipped in -short mode")
+		}
+		if agentName == "" {
+			resolved, err := mdtest.ResolveAgent(mdtest.AgentAuto, mdtest.DefaultLookPath)
+			if err != nil {
+				t.Skipf("mdtesting: %v", err)
+			}
+			agentName = resolved
+		}
+	} else if agentName == "" {
+		agentName = mdtest.Claude
+	}
+
+	output := &lineLogger{t: t}
+	deps := mdtest.DefaultDependencies(io.Discard)
+	deps.Reporters = []mdtest.Reporter{&testReporter{output: output}}
+	if opts.Exec != nil {
+		deps.Exec = opts.Exec
+	}
+	suite, err := mdtest.Run(context.Background(), mdtest.Config{
+		Root:                       root,
+		Files:                      []string{rel},
+		Agent:                      agentName,
+		DangerouslyAllowAllActions: opts.DangerouslyAllowAllActions,
+		Vars:                       opts.Vars,
+		PromptMode:                 opts.PromptMode,
+		StrictLog:                  opts.StrictLog,
+		RunXFail:                   opts.RunXFail,
+		RepairLimit:                opts.RepairLimit,
+	}, deps)
+	output.flush()
+	if err != nil {
+		t.Fatalf("mdtesting: %v", err)
+	}
+
+	for _, hook := range suite.Hooks {
+		if hook.Status != mdtest.TestPass {
+			t.Errorf("%s %s failed: %s", hook.Kind, hook.HookRel, hook.Reason)
+		}
+	}
+	if len(suite.Results) == 1 {
+		report(t, suite.Results[0])
+		return
+	}
+	// Matrix files expand into one result per parameter combination, named
+	// like card=visa.
+	for _, result := range suite.Results {
+		name := strings.Trim(strings.TrimPrefix(result.ID, result.TestRel), "[]")
+		t.Run(name, func(t *testing.T) {
+			report(t, result)
+		})
+	}
+}
+
+// tb is the part of testing.TB that report uses.
+type tb interface {
+	Helper()
+	Log(args ...any)
+	Errorf(format string, args ...any)
+	Skip(args ...any)
+}
+
+// report maps result onto t.
+func report(t tb, result mdtest.TestResult) {
+	t.Helper()
+	if result.LogAbs != "" {
+		t.Log("log:", result.LogAbs)
+	}
+	for _, c := range result.Cases {
+		if c.Status != mdtest.TestPass {
+			t.Errorf("case %q failed: %s", c.Name, c.Reason)
+		}
+	}
+	switch result.Status {
+	case mdtest.TestPass:
+	case mdtest.TestSkip:
+		t.Skip(strings.TrimPrefix(result.Reason, "skip: "))
+	case mdtest.TestXFail:
+		t.Log("expected failure:", strings.TrimPrefix(result.Reason, "xfail: "))
+	default:
+		t.Errorf("%s: %s", result.Status, result.Reason)
+	}
+}
+
+// testReporter sends agent output to the test log, where go test shows it
+// with -v or when the test fails.
+type testReporter struct {
+	mdtest.BaseReporter
+	output *lineLogger
+}
+
+func (r *testReporter) AgentOutput(string) io.Writer {
+	return r.output
+}
+
+// lineLogger calls t.Log once per line written to it.
+type lineLogger struct {
+	t       *testing.T
+	mu      sync.Mutex
+	partial bytes.Buffer
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partial.Write(p)
+	for {
+		line, err := l.partial.ReadString('\n')
+		if err != nil {
+			rest := line
+			l.partial.Reset()
+			l.partial.WriteString(rest)
+			break
+		}
+		l.t.Log(strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// flush logs output left without a trailing newline.
+func (l *lineLogger) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.partial.Len() > 0 {
+		l.t.Log(strings.TrimRight(l.partial.String(), "\r\n"))
+		l.partial.Reset()
+	}
+}
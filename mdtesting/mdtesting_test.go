@@ -0,0 +1,169 @@
+package mdtesting
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PeronGH/mdtest-cli/mdtest"
+)
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+}
+
+func TestRunRunsEachFileAsSubtest(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "testdata", "login.test.md"), "# Login\n")
+	mustWriteFile(t, filepath.Join(root, "testdata", "pay.test.md"), "---\nmatrix:\n  card: [visa, amex]\n---\n# Pay with ${card}\n")
+	mustWriteFile(t, filepath.Join(root, "testdata", "later.test.md"), "---\nskip: needs staging\n---\n")
+	mustWriteFile(t, filepath.Join(root, "testdata", "notes.md"), "not a test\n")
+
+	var mu sync.Mutex
+	var ran []string
+	exec := func(_ context.Context, req mdtest.ExecRequest) (mdtest.ExecResult, error) {
+		mu.Lock()
+		ran = append(ran, req.ID)
+		mu.Unlock()
+		if !strings.Contains(req.Prompt, req.LogAbs) {
+			return mdtest.ExecResult{}, fmt.Errorf("prompt does not mention %s", req.LogAbs)
+		}
+		return mdtest.ExecResult{}, os.WriteFile(req.LogAbs, []byte("---\nstatus: pass\n---\n\nDone.\n"), 0o644)
+	}
+
+	Run(t, "testdata/*", Options{Root: root, Exec: exec})
+
+	sort.Strings(ran)
+	want := []string{"testdata/login.test.md", "testdata/pay.test.md[card=amex]", "testdata/pay.test.md[card=visa]"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Fatalf("agent ran for %#v, want %#v", ran, want)
+	}
+}
+
+func TestRunWithRelativeRoot(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "e2e", "flows", "login.test.md"), "# Login\n")
+	t.Chdir(dir)
+
+	var ran []string
+	exec := func(_ context.Context, req mdtest.ExecRequest) (mdtest.ExecResult, error) {
+		ran = append(ran, req.TestAbs)
+		return mdtest.ExecResult{}, os.WriteFile(req.LogAbs, []byte("---\nstatus: pass\n---\n\nDone.\n"), 0o644)
+	}
+
+	Run(t, "flows/*.test.md", Options{Root: "e2e", Exec: exec})
+
+	want := []string{filepath.Join(dir, "e2e", "flows", "login.test.md")}
+	if !reflect.DeepEqual(ran, want) {
+		t.Fatalf("agent ran for %#v, want %#v", ran, want)
+	}
+}
+
+type fakeTB struct {
+	logs    []string
+	errors  []string
+	skipped string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Log(args ...any) {
+	f.logs = append(f.logs, fmt.Sprintln(args...))
+}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Skip(args ...any) {
+	f.skipped = fmt.Sprint(args...)
+}
+
+func TestReportMapsStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     mdtest.TestResult
+		wantErrors []string
+		wantSkip   string
+	}{
+		{
+			name:   "pass",
+			result: mdtest.TestResult{Status: mdtest.TestPass, LogAbs: "/suite/a.logs/1.log.md"},
+		},
+		{
+			name:       "fail",
+			result:     mdtest.TestResult{Status: mdtest.TestFail, Reason: "total was wrong (agent exit code 0)"},
+			wantErrors: []string{"fail: total was wrong (agent exit code 0)"},
+		},
+		{
+			name: "failed case",
+			result: mdtest.TestResult{Status: mdtest.TestFail, Reason: "1 of 2 cases failed", Cases: []mdtest.CaseResult{
+				{Name: "guest", Status: mdtest.TestPass},
+				{Name: "admin", Status: mdtest.TestFail, Reason: "no menu"},
+			}},
+			wantErrors: []string{`case "admin" failed: no menu`, "fail: 1 of 2 cases failed"},
+		},
+		{
+			name:       "error",
+			result:     mdtest.TestResult{Status: mdtest.TestError, Reason: "before fixture exited 1"},
+			wantErrors: []string{"error: before fixture exited 1"},
+		},
+		{
+			name:     "skip",
+			result:   mdtest.TestResult{Status: mdtest.TestSkip, Reason: "skip: needs staging"},
+			wantSkip: "needs staging",
+		},
+		{
+			name:   "xfail",
+			result: mdtest.TestResult{Status: mdtest.TestXFail, Reason: "xfail: BUG-12; total was wrong"},
+		},
+		{
+			name:       "xpass",
+			result:     mdtest.TestResult{Status: mdtest.TestXPass, Reason: "expected to fail (BUG-12) but passed"},
+			wantErrors: []string{"xpass: expected to fail (BUG-12) but passed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeTB{}
+			report(f, tt.result)
+			if !reflect.DeepEqual(f.errors, tt.wantErrors) {
+				t.Fatalf("errors = %#v, want %#v", f.errors, tt.wantErrors)
+			}
+			if f.skipped != tt.wantSkip {
+				t.Fatalf("skipped = %q, want %q", f.skipped, tt.wantSkip)
+			}
+			if tt.result.LogAbs != "" && (len(f.logs) == 0 || f.logs[0] != "log: "+tt.result.LogAbs+"\n") {
+				t.Fatalf("logs = %#v, want the log path first", f.logs)
+			}
+		})
+	}
+}
+
+func TestRunWithFakeAgent(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "testdata", "login.test.md"), "---\nfake:\n  observed:\n    user: alice\n---\n# Login\n")
+
+	Run(t, "testdata/*.test.md", Options{Root: root, Agent: mdtest.Fake})
+
+	logs, err := filepath.Glob(filepath.Join(root, "testdata", "login.logs", "*.log.md"))
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("logs = %#v, %v, want one log from the fake agent", logs, err)
+	}
+	log, err := mdtest.ParseLog(logs[0])
+	if err != nil || log.Observed["user"] != "alice" {
+		t.Fatalf("ParseLog = %#v, %v, want the scripted observation", log, err)
+	}
+}